@@ -0,0 +1,61 @@
+package poker
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// embeddedAssets are the pages and the files under /static/, built into the
+// binary so the server runs from any directory.
+//
+//go:embed *.html static
+var embeddedAssets embed.FS
+
+// UseAssetsDir serves the pages and static files from dir instead of the ones
+// built in, reading them on every request so edits show up without a
+// restart.
+func (s *PlayerServer) UseAssetsDir(dir string) error {
+	assets := os.DirFS(dir)
+
+	parsed, err := parsePages(assets)
+	if err != nil {
+		return fmt.Errorf("problem using assets in %s, %v", dir, err)
+	}
+
+	s.pages = parsed
+	s.assets = assets
+	s.assetsDir = dir
+	return nil
+}
+
+// staticHandler serves the files under static/ at /static/.
+func (s *PlayerServer) staticHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/static/")
+	if !fs.ValidPath(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := fs.ReadFile(s.assets, "static/"+name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Static URLs stay the same across deploys, so browsers check back on
+	// every use rather than running an old client against a new server. The
+	// ETag lets an unchanged file be answered with a 304.
+	sum := sha256.Sum256(data)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
@@ -0,0 +1,114 @@
+package poker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestEmbeddedAssets(t *testing.T) {
+	t.Run("the server starts outside the app directory", func(t *testing.T) {
+		wd, err := os.Getwd()
+		poker.AssertNoError(t, err)
+		poker.AssertNoError(t, os.Chdir(t.TempDir()))
+		defer os.Chdir(wd)
+
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest())
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("static files are served with caching headers", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/static/game.js", ""))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		if got := response.Header().Get("Content-Type"); !strings.Contains(got, "javascript") {
+			t.Errorf("got content type %q want javascript", got)
+		}
+		if got := response.Header().Get("Cache-Control"); got != "no-cache" {
+			t.Errorf("got cache control %q want no-cache so a deploy's client is picked up", got)
+		}
+
+		etag := response.Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("expected an ETag")
+		}
+
+		request := newPlayersRequest(http.MethodGet, "/static/game.js", "")
+		request.Header.Set("If-None-Match", etag)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotModified)
+	})
+
+	t.Run("unknown static files are not found", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		for _, path := range []string{"/static/", "/static/nope.js", "/static/../game.html"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodGet, path, ""))
+
+			if response.Code == http.StatusOK {
+				t.Errorf("expected %s not to be served", path)
+			}
+		}
+	})
+}
+
+func TestAssetsDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, page := range []string{"layout.html", "league.html", "player.html", "games.html", "login.html"} {
+		contents, err := os.ReadFile(page)
+		poker.AssertNoError(t, err)
+		writeAsset(t, dir, page, string(contents))
+	}
+	writeAsset(t, dir, "game.html", "first")
+	writeAsset(t, dir, "static/game.js", "console.log('dev')")
+
+	server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+	poker.AssertNoError(t, server.UseAssetsDir(dir))
+
+	t.Run("the game page is reloaded on every request", func(t *testing.T) {
+		for _, page := range []string{"first", "second"} {
+			writeAsset(t, dir, "game.html", page)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGameRequest())
+			poker.AssertResponseBody(t, response.Body.String(), page)
+		}
+	})
+
+	t.Run("static files are checked for changes on every use", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/static/game.js", ""))
+
+		poker.AssertResponseBody(t, response.Body.String(), "console.log('dev')")
+		if got := response.Header().Get("Cache-Control"); got != "no-cache" {
+			t.Errorf("got cache control %q want no-cache", got)
+		}
+	})
+
+	t.Run("a directory without a game page is refused", func(t *testing.T) {
+		if err := server.UseAssetsDir(t.TempDir()); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func writeAsset(t testing.TB, dir, name, contents string) {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	poker.AssertNoError(t, os.MkdirAll(filepath.Dir(path), 0777))
+	poker.AssertNoError(t, os.WriteFile(path, []byte(contents), 0666))
+}
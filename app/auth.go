@@ -0,0 +1,202 @@
+package poker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCookie = "poker_session"
+	// DefaultSessionLength is how long a browser stays logged in.
+	DefaultSessionLength = 12 * time.Hour
+)
+
+// sessions remember browsers that logged in with a token. Only the token's
+// hash is kept, so revoking the token ends its sessions too.
+type sessions struct {
+	length time.Duration
+
+	mu   sync.Mutex
+	byID map[string]session
+}
+
+type session struct {
+	tokenHash string
+	expires   time.Time
+}
+
+func newSessions(length time.Duration) *sessions {
+	return &sessions{length: length, byID: map[string]session{}}
+}
+
+func (s *sessions) start(tokenHash string) (string, time.Time) {
+	id := make([]byte, 32)
+	rand.Read(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range s.byID {
+		if now.After(session.expires) {
+			delete(s.byID, id)
+		}
+	}
+
+	expires := now.Add(s.length)
+	s.byID[hex.EncodeToString(id)] = session{tokenHash: tokenHash, expires: expires}
+	return hex.EncodeToString(id), expires
+}
+
+func (s *sessions) tokenHash(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.byID[id]
+	if !ok || time.Now().After(session.expires) {
+		delete(s.byID, id)
+		return "", false
+	}
+
+	return session.tokenHash, true
+}
+
+func (s *sessions) end(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.byID, id)
+}
+
+// UseAuth makes every request but logging in and static files authenticate
+// with a token from tokens, either as a bearer token or through a session
+// started at /login, and checks the token's role allows the request.
+func (s *PlayerServer) UseAuth(tokens TokenStore) {
+	s.tokens = tokens
+	s.sessions = newSessions(DefaultSessionLength)
+}
+
+// authorize passes requests on to next once they have the role they need.
+// Browsers are sent to log in, and everything else is told it's unauthorized.
+func (s *PlayerServer) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required := requiredRole(r)
+		if s.tokens == nil || required == RoleNone {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := s.authenticate(r)
+		switch {
+		case !ok && r.Method == http.MethodGet && prefersHTML(r):
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+		case !ok:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="poker"`)
+			http.Error(w, "a token is required", http.StatusUnauthorized)
+		case token.Role < required:
+			http.Error(w, "the "+token.Role.String()+" role can't do that, it needs "+required.String(), http.StatusForbidden)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// authenticate finds the token of a request's bearer token or session.
+func (s *PlayerServer) authenticate(r *http.Request) (APIToken, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return s.tokens.Token(HashToken(strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))))
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return APIToken{}, false
+	}
+
+	hash, ok := s.sessions.tokenHash(cookie.Value)
+	if !ok {
+		return APIToken{}, false
+	}
+
+	return s.tokens.Token(hash)
+}
+
+// requiredRole is the least role that can make a request. Anyone can log in
+// and get static files, viewers can read, dealers can play games and record
+// wins, and every other change needs an admin.
+func requiredRole(r *http.Request) Role {
+	path := r.URL.Path
+
+	switch {
+	case path == "/login" || path == "/logout" || strings.HasPrefix(path, "/static/"):
+		return RoleNone
+	case path == "/ws" || strings.HasPrefix(path, "/games/") && strings.HasSuffix(path, "/ws"):
+		return RoleDealer
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return RoleViewer
+	case r.Method == http.MethodPost && (path == "/games" || isRecordWinPath(r.URL.EscapedPath())):
+		return RoleDealer
+	}
+
+	return RoleAdmin
+}
+
+func isRecordWinPath(path string) bool {
+	name := strings.TrimPrefix(path, "/players/")
+	return name != path && name != "" && !strings.Contains(name, "/")
+}
+
+// loginHandler starts a session for a browser that has a token, sending it
+// on to where it was going.
+func (s *PlayerServer) loginHandler(w http.ResponseWriter, r *http.Request) {
+	if s.tokens == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		next = "/league"
+	}
+
+	if r.Method != http.MethodPost {
+		s.renderPage(w, http.StatusOK, "login.html", struct{ Next, Error string }{next, ""})
+		return
+	}
+
+	hash := HashToken(strings.TrimSpace(r.FormValue("token")))
+	if _, ok := s.tokens.Token(hash); !ok {
+		s.renderPage(w, http.StatusUnauthorized, "login.html", struct{ Next, Error string }{next, "That token isn't valid"})
+		return
+	}
+
+	id, expires := s.sessions.start(hash)
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func (s *PlayerServer) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	if s.tokens == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.sessions.end(cookie.Value)
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
@@ -0,0 +1,150 @@
+package poker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestAuth(t *testing.T) {
+	tokens, err := poker.TokenStoreFromFile(filepath.Join(t.TempDir(), "tokens.json"))
+	poker.AssertNoError(t, err)
+
+	secrets := map[poker.Role]string{}
+	for _, role := range []poker.Role{poker.RoleViewer, poker.RoleDealer, poker.RoleAdmin} {
+		secrets[role], err = tokens.Create(role.String(), role, time.Now())
+		poker.AssertNoError(t, err)
+	}
+
+	store := &poker.StubPlayerStore{League: poker.League{{"Pepper", 3}}}
+	server := mustCreatePlayerServer(t, store, dummyGame)
+	server.UseAuth(tokens)
+
+	serve := func(request *http.Request, secret string) *httptest.ResponseRecorder {
+		if secret != "" {
+			request.Header.Set("Authorization", "Bearer "+secret)
+		}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("roles allow what they should and nothing more", func(t *testing.T) {
+		deletePlayer, _ := http.NewRequest(http.MethodDelete, "/players/Pepper", nil)
+
+		cases := []struct {
+			request *http.Request
+			role    poker.Role
+			want    int
+		}{
+			{newLeagueRequest(), poker.RoleViewer, http.StatusOK},
+			{newPostWinRequest("Pepper"), poker.RoleViewer, http.StatusForbidden},
+			{newPostWinRequest("Pepper"), poker.RoleDealer, http.StatusAccepted},
+			{deletePlayer, poker.RoleDealer, http.StatusForbidden},
+			{deletePlayer, poker.RoleAdmin, http.StatusNoContent},
+		}
+
+		for _, c := range cases {
+			response := serve(c.request.Clone(c.request.Context()), secrets[c.role])
+			if response.Code != c.want {
+				t.Errorf("%s %s as %s got status %d want %d", c.request.Method, c.request.URL.Path, c.role, response.Code, c.want)
+			}
+		}
+	})
+
+	t.Run("requests without a token are unauthorized", func(t *testing.T) {
+		response := serve(newPostWinRequest("Pepper"), "")
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusUnauthorized)
+		if got := response.Header().Get("WWW-Authenticate"); got == "" {
+			t.Error("didn't say how to authenticate")
+		}
+
+		response = serve(newLeagueRequest(), "pk_not_a_token")
+		poker.AssertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("browsers are sent to log in", func(t *testing.T) {
+		request := newLeagueRequest()
+		request.Header.Set("Accept", "text/html")
+		response := serve(request, "")
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusSeeOther)
+		if got, want := response.Header().Get("Location"), "/login?next=%2Fleague"; got != want {
+			t.Errorf("got location %q want %q", got, want)
+		}
+	})
+
+	t.Run("logging in starts a session until logging out", func(t *testing.T) {
+		form := url.Values{"token": {secrets[poker.RoleViewer]}, "next": {"/players/Pepper"}}
+		login, _ := http.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		login.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		response := serve(login, "")
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusSeeOther)
+		if got, want := response.Header().Get("Location"), "/players/Pepper"; got != want {
+			t.Errorf("got location %q want %q", got, want)
+		}
+
+		cookies := response.Result().Cookies()
+		if len(cookies) != 1 || !cookies[0].HttpOnly {
+			t.Fatalf("got cookies %+v want one http only session cookie", cookies)
+		}
+
+		withSession := func(request *http.Request) *http.Request {
+			request.AddCookie(cookies[0])
+			return request
+		}
+
+		poker.AssertResponseStatus(t, serve(withSession(newLeagueRequest()), "").Code, http.StatusOK)
+		poker.AssertResponseStatus(t, serve(withSession(newPostWinRequest("Pepper")), "").Code, http.StatusForbidden)
+
+		logout, _ := http.NewRequest(http.MethodPost, "/logout", nil)
+		serve(withSession(logout), "")
+
+		poker.AssertResponseStatus(t, serve(withSession(newLeagueRequest()), "").Code, http.StatusUnauthorized)
+	})
+
+	t.Run("logging in with a bad token shows the form again", func(t *testing.T) {
+		form := url.Values{"token": {"pk_not_a_token"}}
+		login, _ := http.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		login.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		response := serve(login, "")
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusUnauthorized)
+		if len(response.Result().Cookies()) != 0 {
+			t.Error("started a session for a bad token")
+		}
+	})
+
+	t.Run("login only sends browsers on within the site", func(t *testing.T) {
+		form := url.Values{"token": {secrets[poker.RoleViewer]}, "next": {"//evil.example"}}
+		login, _ := http.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		login.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		response := serve(login, "")
+
+		if got, want := response.Header().Get("Location"), "/league"; got != want {
+			t.Errorf("got location %q want %q", got, want)
+		}
+	})
+
+	t.Run("revoked tokens stop working", func(t *testing.T) {
+		secret, err := tokens.Create("temp", poker.RoleAdmin, time.Now())
+		poker.AssertNoError(t, err)
+		poker.AssertResponseStatus(t, serve(newLeagueRequest(), secret).Code, http.StatusOK)
+
+		poker.AssertNoError(t, tokens.Revoke("temp"))
+		poker.AssertResponseStatus(t, serve(newLeagueRequest(), secret).Code, http.StatusUnauthorized)
+	})
+
+	t.Run("static files need no token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/static/game.js", nil)
+		poker.AssertResponseStatus(t, serve(request, "").Code, http.StatusOK)
+	})
+}
@@ -1,28 +1,99 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"os"
 	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
+// BlindAlerter schedules an update that is dropped if ctx is done before it's
+// due.
 type BlindAlerter interface {
-	ScheduleAlertAt(duration time.Duration, amount int, to io.Writer)
+	ScheduleAlertAt(ctx context.Context, duration time.Duration, update BlindUpdate, to io.Writer)
+}
+
+type BlindAlerterFunc func(ctx context.Context, duration time.Duration, update BlindUpdate, to io.Writer)
+
+func (a BlindAlerterFunc) ScheduleAlertAt(ctx context.Context, duration time.Duration, update BlindUpdate, to io.Writer) {
+	a(ctx, duration, update, to)
+}
+
+// BlindUpdate announces the level a game has reached. Breaks have a Level of
+// 0, and NextChangeAt is nil at the final level or while the blinds are
+// paused.
+type BlindUpdate struct {
+	Level        int        `json:"level"`
+	SmallBlind   int        `json:"small_blind"`
+	BigBlind     int        `json:"big_blind"`
+	Ante         int        `json:"ante"`
+	Break        bool       `json:"break"`
+	NextChangeAt *time.Time `json:"next_change_at,omitempty"`
+
+	// Lasts is how long the level runs for once it's announced, which the
+	// alerter turns into NextChangeAt.
+	Lasts time.Duration `json:"-"`
+}
+
+// BlindUpdateWriter is a destination that takes blind updates as they are
+// rather than as text.
+type BlindUpdateWriter interface {
+	WriteBlindUpdate(update BlindUpdate) error
 }
 
-type BlindAlerterFunc func(duration time.Duration, amount int, to io.Writer)
+// AnnounceBlind tells to about update, as text unless it's a
+// BlindUpdateWriter.
+func AnnounceBlind(to io.Writer, update BlindUpdate) error {
+	if writer, ok := to.(BlindUpdateWriter); ok {
+		return writer.WriteBlindUpdate(update)
+	}
 
-func (a BlindAlerterFunc) ScheduleAlertAt(duration time.Duration, amount int, to io.Writer) {
-	a(duration, amount, to)
+	if update.Break {
+		_, err := fmt.Fprint(to, "Break, blinds are paused\n")
+		return err
+	}
+
+	_, err := fmt.Fprintf(to, "Blind is now %d\n", update.SmallBlind)
+	return err
+}
+
+type ScheduledAlert struct {
+	At     time.Duration
+	Amount int
+}
+
+func (s ScheduledAlert) String() string {
+	return fmt.Sprintf("%d chips at %v", s.Amount, s.At)
 }
 
-func Alerter(duration time.Duration, amount int, to io.Writer) {
-	time.AfterFunc(duration, func() {
-		fmt.Fprintf(to, "Blind is now %d\n", amount)
+// NewAlerter creates an alerter that times blinds with c.
+func NewAlerter(c clock.Clock) BlindAlerter {
+	return BlindAlerterFunc(func(ctx context.Context, duration time.Duration, update BlindUpdate, to io.Writer) {
+		alertAt(c, ctx, duration, update, to)
 	})
 }
 
-func StdOutAlerter(duration time.Duration, amount int) {
-	Alerter(duration, amount, os.Stdout)
+// alertAt announces update to to after duration, unless ctx is done first.
+func alertAt(c clock.Clock, ctx context.Context, duration time.Duration, update BlindUpdate, to io.Writer) {
+	timer := c.AfterFunc(duration, func() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if update.Lasts > 0 {
+			next := c.Now().Add(update.Lasts).UTC()
+			update.NextChangeAt = &next
+		}
+
+		AnnounceBlind(to, update)
+	})
+
+	if ctx.Done() != nil {
+		go func() {
+			<-ctx.Done()
+			timer.Stop()
+		}()
+	}
 }
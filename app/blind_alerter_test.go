@@ -0,0 +1,78 @@
+package poker_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
+)
+
+func TestAlerter(t *testing.T) {
+	blind := poker.BlindUpdate{Level: 1, SmallBlind: 100, BigBlind: 200}
+
+	t.Run("alerts when the blind is due", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		out := &bytes.Buffer{}
+
+		poker.NewAlerter(fake).ScheduleAlertAt(context.Background(), time.Minute, blind, out)
+
+		fake.Advance(time.Minute - time.Nanosecond)
+		assertMessagesSentToUser(t, out)
+
+		fake.Advance(time.Nanosecond)
+		assertMessagesSentToUser(t, out, "Blind is now 100\n")
+	})
+
+	t.Run("announces breaks", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		out := &bytes.Buffer{}
+
+		poker.NewAlerter(fake).ScheduleAlertAt(context.Background(), time.Minute, poker.BlindUpdate{Break: true}, out)
+		fake.Advance(time.Minute)
+
+		assertMessagesSentToUser(t, out, "Break, blinds are paused\n")
+	})
+
+	t.Run("gives updates to a BlindUpdateWriter with when the level ends", func(t *testing.T) {
+		start := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+		fake := clock.NewFake(start)
+		out := &spyUpdateWriter{}
+
+		update := blind
+		update.Lasts = 10 * time.Minute
+		poker.NewAlerter(fake).ScheduleAlertAt(context.Background(), time.Minute, update, out)
+		fake.Advance(time.Minute)
+
+		nextChange := start.Add(11 * time.Minute)
+		want := []poker.BlindUpdate{{Level: 1, SmallBlind: 100, BigBlind: 200, NextChangeAt: &nextChange, Lasts: 10 * time.Minute}}
+		if !reflect.DeepEqual(out.updates, want) {
+			t.Errorf("got updates %+v want %+v", out.updates, want)
+		}
+	})
+
+	t.Run("no alert fires after cancellation", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		out := &bytes.Buffer{}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		poker.NewAlerter(fake).ScheduleAlertAt(ctx, time.Minute, blind, out)
+		cancel()
+		fake.Advance(time.Hour)
+
+		assertMessagesSentToUser(t, out)
+	})
+}
+
+type spyUpdateWriter struct {
+	bytes.Buffer
+	updates []poker.BlindUpdate
+}
+
+func (s *spyUpdateWriter) WriteBlindUpdate(update poker.BlindUpdate) error {
+	s.updates = append(s.updates, update)
+	return nil
+}
@@ -0,0 +1,300 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BlindLevel is one step of a blind structure. A break has a duration but no
+// blinds, and pauses the schedule before the next level.
+type BlindLevel struct {
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+	Duration   time.Duration
+	Break      bool
+}
+
+// BlindStructure is the schedule of blinds for a game. PerPlayer is added to
+// the duration of every level for each player at the table.
+type BlindStructure struct {
+	Name      string
+	PerPlayer time.Duration
+	Levels    []BlindLevel
+}
+
+const (
+	StandardBlinds  = "standard"
+	TurboBlinds     = "turbo"
+	DeepStackBlinds = "deep-stack"
+)
+
+var blindPresets = map[string]BlindStructure{
+	StandardBlinds: {
+		Name:      StandardBlinds,
+		PerPlayer: time.Minute,
+		Levels: levelsOf(5*time.Minute,
+			100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000),
+	},
+	TurboBlinds: {
+		Name: TurboBlinds,
+		Levels: levelsOf(3*time.Minute,
+			100, 200, 400, 600, 1000, 2000, 4000, 8000),
+	},
+	DeepStackBlinds: {
+		Name: DeepStackBlinds,
+		Levels: withBreaks(levelsOf(20*time.Minute,
+			25, 50, 75, 100, 150, 200, 300, 400, 600, 800, 1000, 1500, 2000, 3000, 4000),
+			4, 10*time.Minute),
+	},
+}
+
+// BlindPresets returns the names of the built in blind structures.
+func BlindPresets() []string {
+	names := make([]string, 0, len(blindPresets))
+	for name := range blindPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// BlindPreset returns the built in blind structure called name.
+func BlindPreset(name string) (BlindStructure, error) {
+	blinds, ok := blindPresets[name]
+	if !ok {
+		return BlindStructure{}, fmt.Errorf("unknown blind structure %q, expected one of %s", name, strings.Join(BlindPresets(), ", "))
+	}
+
+	return blinds, nil
+}
+
+// LookupBlindStructure returns the preset called nameOrPath, or else the
+// structure in the file at that path.
+func LookupBlindStructure(nameOrPath string) (BlindStructure, error) {
+	if blinds, ok := blindPresets[nameOrPath]; ok {
+		return blinds, nil
+	}
+
+	return BlindStructureFromFile(nameOrPath)
+}
+
+// BlindStructureFromFile reads a blind structure from a .json, .yaml or .yml
+// file, using the file name when the structure has no name.
+func BlindStructureFromFile(path string) (BlindStructure, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("problem reading blind structure %s, %v", path, err)
+	}
+
+	var file blindStructureFile
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&file)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&file)
+	default:
+		return BlindStructure{}, fmt.Errorf("unknown blind structure format %q, expected .json, .yaml or .yml", ext)
+	}
+
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("problem parsing blind structure %s, %v", path, err)
+	}
+
+	blinds, err := file.structure()
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("problem parsing blind structure %s, %v", path, err)
+	}
+
+	if blinds.Name == "" {
+		blinds.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if err := blinds.Validate(); err != nil {
+		return BlindStructure{}, fmt.Errorf("invalid blind structure %s, %v", path, err)
+	}
+
+	return blinds, nil
+}
+
+// Validate checks that the structure starts with a level, that every level
+// lasts some time and that blinds never go down.
+func (b BlindStructure) Validate() error {
+	if len(b.Levels) == 0 {
+		return errors.New("no levels")
+	}
+
+	if b.PerPlayer < 0 {
+		return fmt.Errorf("negative per player duration %v", b.PerPlayer)
+	}
+
+	previous := BlindLevel{}
+	for i, level := range b.Levels {
+		if err := level.validate(previous, b.PerPlayer); err != nil {
+			return fmt.Errorf("level %d: %v", i+1, err)
+		}
+
+		if !level.Break {
+			previous = level
+		}
+	}
+
+	if b.Levels[0].Break {
+		return errors.New("level 1: can't start with a break")
+	}
+
+	return nil
+}
+
+func (l BlindLevel) validate(previous BlindLevel, perPlayer time.Duration) error {
+	if l.Duration < 0 {
+		return fmt.Errorf("negative duration %v", l.Duration)
+	}
+
+	if l.Break {
+		if l.Duration == 0 {
+			return errors.New("break has no duration")
+		}
+		if l.SmallBlind != 0 || l.BigBlind != 0 || l.Ante != 0 {
+			return errors.New("break can't have blinds")
+		}
+		return nil
+	}
+
+	if l.Duration == 0 && perPlayer == 0 {
+		return errors.New("no duration")
+	}
+
+	switch {
+	case l.SmallBlind <= 0:
+		return fmt.Errorf("small blind %d must be positive", l.SmallBlind)
+	case l.BigBlind < l.SmallBlind:
+		return fmt.Errorf("big blind %d is less than small blind %d", l.BigBlind, l.SmallBlind)
+	case l.Ante < 0:
+		return fmt.Errorf("negative ante %d", l.Ante)
+	case l.SmallBlind < previous.SmallBlind || l.BigBlind < previous.BigBlind:
+		return fmt.Errorf("blinds %d/%d are lower than the level before", l.SmallBlind, l.BigBlind)
+	}
+
+	return nil
+}
+
+// timedUpdate is a blind update and when it's due after the game starts.
+type timedUpdate struct {
+	At time.Duration
+	BlindUpdate
+}
+
+// timeline returns the update for each level and when it starts for a table
+// of numberOfPlayers. Only breaks are left out of the level numbers.
+func (b BlindStructure) timeline(numberOfPlayers int) []timedUpdate {
+	var timeline []timedUpdate
+
+	at, number := time.Duration(0), 0
+	for i, level := range b.Levels {
+		length := level.Duration
+		update := BlindUpdate{Break: true}
+
+		if !level.Break {
+			number++
+			length += time.Duration(numberOfPlayers) * b.PerPlayer
+			update = BlindUpdate{Level: number, SmallBlind: level.SmallBlind, BigBlind: level.BigBlind, Ante: level.Ante}
+		}
+
+		if i < len(b.Levels)-1 {
+			update.Lasts = length
+		}
+
+		timeline = append(timeline, timedUpdate{at, update})
+		at += length
+	}
+
+	return timeline
+}
+
+type blindStructureFile struct {
+	Name      string           `json:"name" yaml:"name"`
+	PerPlayer string           `json:"per_player" yaml:"per_player"`
+	Levels    []blindLevelFile `json:"levels" yaml:"levels"`
+}
+
+type blindLevelFile struct {
+	SmallBlind int    `json:"small_blind" yaml:"small_blind"`
+	BigBlind   int    `json:"big_blind" yaml:"big_blind"`
+	Ante       int    `json:"ante" yaml:"ante"`
+	Duration   string `json:"duration" yaml:"duration"`
+	Break      string `json:"break" yaml:"break"`
+}
+
+func (f blindStructureFile) structure() (BlindStructure, error) {
+	blinds := BlindStructure{Name: f.Name}
+
+	var err error
+	if blinds.PerPlayer, err = parseBlindDuration(f.PerPlayer); err != nil {
+		return BlindStructure{}, fmt.Errorf("per_player: %v", err)
+	}
+
+	for i, level := range f.Levels {
+		if level.Break != "" && level.Duration != "" {
+			return BlindStructure{}, fmt.Errorf("level %d: has both a break and a duration", i+1)
+		}
+
+		duration, err := parseBlindDuration(level.Duration + level.Break)
+		if err != nil {
+			return BlindStructure{}, fmt.Errorf("level %d: %v", i+1, err)
+		}
+
+		blinds.Levels = append(blinds.Levels, BlindLevel{
+			SmallBlind: level.SmallBlind,
+			BigBlind:   level.BigBlind,
+			Ante:       level.Ante,
+			Duration:   duration,
+			Break:      level.Break != "",
+		})
+	}
+
+	return blinds, nil
+}
+
+func parseBlindDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+func levelsOf(duration time.Duration, smallBlinds ...int) []BlindLevel {
+	levels := make([]BlindLevel, len(smallBlinds))
+	for i, blind := range smallBlinds {
+		levels[i] = BlindLevel{SmallBlind: blind, BigBlind: 2 * blind, Duration: duration}
+	}
+
+	return levels
+}
+
+func withBreaks(levels []BlindLevel, every int, duration time.Duration) []BlindLevel {
+	var withBreaks []BlindLevel
+	for i, level := range levels {
+		if i > 0 && i%every == 0 {
+			withBreaks = append(withBreaks, BlindLevel{Duration: duration, Break: true})
+		}
+		withBreaks = append(withBreaks, level)
+	}
+
+	return withBreaks
+}
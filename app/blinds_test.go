@@ -0,0 +1,120 @@
+package poker_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestBlindPresets(t *testing.T) {
+	for _, name := range poker.BlindPresets() {
+		t.Run(name, func(t *testing.T) {
+			blinds, err := poker.LookupBlindStructure(name)
+			poker.AssertNoError(t, err)
+			poker.AssertNoError(t, blinds.Validate())
+
+			if blinds.Name != name {
+				t.Errorf("got name %q want %q", blinds.Name, name)
+			}
+		})
+	}
+
+	t.Run("unknown preset", func(t *testing.T) {
+		if _, err := poker.BlindPreset("glacial"); err == nil {
+			t.Error("expected an error for an unknown preset")
+		}
+	})
+}
+
+func TestBlindStructureFromFile(t *testing.T) {
+	want := poker.BlindStructure{
+		Name:      "club night",
+		PerPlayer: time.Minute,
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 25, BigBlind: 50, Duration: 15 * time.Minute},
+			{Duration: 10 * time.Minute, Break: true},
+			{SmallBlind: 50, BigBlind: 100, Ante: 10, Duration: 15 * time.Minute},
+		},
+	}
+
+	files := map[string]string{
+		"club.json": `{
+			"name": "club night",
+			"per_player": "1m",
+			"levels": [
+				{"small_blind": 25, "big_blind": 50, "duration": "15m"},
+				{"break": "10m"},
+				{"small_blind": 50, "big_blind": 100, "ante": 10, "duration": "15m"}
+			]
+		}`,
+		"club.yaml": `
+name: club night
+per_player: 1m
+levels:
+  - {small_blind: 25, big_blind: 50, duration: 15m}
+  - break: 10m
+  - {small_blind: 50, big_blind: 100, ante: 10, duration: 15m}
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			got, err := poker.LookupBlindStructure(writeBlindsFile(t, name, content))
+			poker.AssertNoError(t, err)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("named after the file without a name", func(t *testing.T) {
+		got, err := poker.BlindStructureFromFile(writeBlindsFile(t, "quick.yml", "levels: [{small_blind: 1, big_blind: 2, duration: 1m}]"))
+		poker.AssertNoError(t, err)
+
+		if got.Name != "quick" {
+			t.Errorf("got name %q want %q", got.Name, "quick")
+		}
+	})
+
+	malformed := map[string]string{
+		"unknown format":    "blinds.txt|",
+		"bad json":          `bad.json|{"levels": [`,
+		"unknown field":     `extra.yaml|levels: [{small_blind: 1, big_blind: 2, duration: 1m, straddle: 4}]`,
+		"bad duration":      `duration.yaml|levels: [{small_blind: 1, big_blind: 2, duration: soon}]`,
+		"no levels":         `empty.json|{"name": "empty"}`,
+		"break and level":   `both.yaml|levels: [{small_blind: 1, big_blind: 2, duration: 1m, break: 1m}]`,
+		"starts on break":   `break.yaml|levels: [{break: 1m}, {small_blind: 1, big_blind: 2, duration: 1m}]`,
+		"no small blind":    `small.yaml|levels: [{big_blind: 2, duration: 1m}]`,
+		"big below small":   `big.yaml|levels: [{small_blind: 4, big_blind: 2, duration: 1m}]`,
+		"negative ante":     `ante.yaml|levels: [{small_blind: 1, big_blind: 2, ante: -1, duration: 1m}]`,
+		"no duration":       `forever.yaml|levels: [{small_blind: 1, big_blind: 2}]`,
+		"blinds going down": `down.yaml|levels: [{small_blind: 2, big_blind: 4, duration: 1m}, {small_blind: 1, big_blind: 2, duration: 1m}]`,
+	}
+
+	for name, file := range malformed {
+		t.Run(name, func(t *testing.T) {
+			fileName, content, _ := strings.Cut(file, "|")
+
+			if _, err := poker.BlindStructureFromFile(writeBlindsFile(t, fileName, content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func writeBlindsFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("could not write blinds file %v", err)
+	}
+
+	return path
+}
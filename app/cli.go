@@ -2,16 +2,18 @@ package poker
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"time"
 )
 
-const PlayerPrompt = "Please enter the number of players: "
+const PlayerPrompt = "Please enter the number of players, or their names separated by commas: "
 
-const BadPlayerInputErrMsg = "Bad value received for number of players, please try again with a number"
+const BadPlayerInputErrMsg = "Bad value received for number of players, please try again with a number or at least two names"
 
 const BadWinnerInputErrMsg = "invalid winner input, expect format of 'PlayerName wins'"
 
@@ -24,20 +26,65 @@ type CLI struct {
 func (c *CLI) PlayPoker() {
 	fmt.Fprint(c.out, PlayerPrompt)
 
-	numberOfPlayers, err := strconv.Atoi(c.readLine())
+	numberOfPlayers, participants, err := extractPlayers(c.readLine())
 	if err != nil {
-		fmt.Fprint(c.out, BadPlayerInputErrMsg)
+		fmt.Fprint(c.out, err)
+		return
+	}
+
+	setup := GameSetup{NumberOfPlayers: numberOfPlayers, Participants: participants}
+	if err := c.game.Start(context.Background(), setup, c.out); err != nil {
+		fmt.Fprint(c.out, err)
 		return
 	}
 
-	c.game.Start(numberOfPlayers, c.out)
+	input := c.readLine()
+	for c.control(input) {
+		input = c.readLine()
+	}
 
-	winner, err := extractWinner(c.readLine())
+	winner, err := extractWinner(input)
 	if err != nil {
 		fmt.Fprint(c.out, err)
 		return
 	}
-	c.game.Finish(winner)
+
+	if err := c.game.Finish(winner); err != nil {
+		fmt.Fprint(c.out, err)
+	}
+}
+
+// PrintSeason writes the standings of the season that now falls in.
+func PrintSeason(out io.Writer, store PlayerStore, now time.Time) {
+	season := SeasonOf(now)
+	league := SeasonLeague(store, season)
+
+	fmt.Fprintf(out, "Season %s\n", season.ID)
+
+	if len(league) == 0 {
+		fmt.Fprintln(out, "No wins yet this season")
+		return
+	}
+
+	for i, player := range league {
+		fmt.Fprintf(out, "%d. %s %d\n", i+1, player.Name, player.Wins)
+	}
+}
+
+// control runs input if it's a blind command, reporting how it went.
+func (c *CLI) control(input string) bool {
+	command, done, ok := blindCommand(c.game, input)
+	if !ok {
+		return false
+	}
+
+	if err := command(); err != nil {
+		fmt.Fprintln(c.out, err)
+	} else {
+		fmt.Fprint(c.out, done)
+	}
+
+	return true
 }
 
 func (c *CLI) readLine() string {
@@ -53,6 +100,28 @@ func NewCLI(in io.Reader, out io.Writer, game Game) *CLI {
 	}
 }
 
+func extractPlayers(userInput string) (int, []string, error) {
+	if numberOfPlayers, err := strconv.Atoi(userInput); err == nil {
+		if numberOfPlayers < 2 {
+			return 0, nil, errors.New(BadPlayerInputErrMsg)
+		}
+		return numberOfPlayers, nil, nil
+	}
+
+	var participants []string
+	for _, name := range strings.Split(userInput, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			participants = append(participants, name)
+		}
+	}
+
+	if len(participants) < 2 {
+		return 0, nil, errors.New(BadPlayerInputErrMsg)
+	}
+
+	return len(participants), participants, nil
+}
+
 func extractWinner(userInput string) (string, error) {
 	if !strings.Contains(userInput, "wins") {
 		return "", errors.New(BadWinnerInputErrMsg)
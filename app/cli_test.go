@@ -2,8 +2,12 @@ package poker_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,23 +15,58 @@ import (
 )
 
 type GameSpy struct {
-	StartCalled bool
-	StartedWith int
-	BlindAlert  []byte
+	mu sync.Mutex
+
+	StartCalled      bool
+	StartedWith      int
+	StartedWithNames []string
+	StartedBlinds    *poker.BlindStructure
+	StartedContext   context.Context
+	StartError       error
+	BlindAlert       *poker.BlindUpdate
 
 	FinishCalled bool
 	FinishedWith string
+
+	Controls     []string
+	ControlError error
 }
 
-func (g *GameSpy) Start(numberOfPlayers int, out io.Writer) {
+func (g *GameSpy) Start(ctx context.Context, setup poker.GameSetup, out io.Writer) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	g.StartCalled = true
-	g.StartedWith = numberOfPlayers
-	out.Write(g.BlindAlert)
+	g.StartedWith = setup.NumberOfPlayers
+	g.StartedWithNames = setup.Participants
+	g.StartedBlinds = setup.Blinds
+	g.StartedContext = ctx
+	if g.BlindAlert != nil {
+		poker.AnnounceBlind(out, *g.BlindAlert)
+	}
+	return g.StartError
 }
 
-func (g *GameSpy) Finish(winner string) {
+func (g *GameSpy) Finish(winner string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	g.FinishCalled = true
 	g.FinishedWith = winner
+	return nil
+}
+
+func (g *GameSpy) Pause() error         { return g.control(poker.PauseCommand) }
+func (g *GameSpy) Resume() error        { return g.control(poker.ResumeCommand) }
+func (g *GameSpy) NextLevel() error     { return g.control(poker.NextCommand) }
+func (g *GameSpy) PreviousLevel() error { return g.control(poker.RewindCommand) }
+
+func (g *GameSpy) control(command string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Controls = append(g.Controls, command)
+	return g.ControlError
 }
 
 func TestCLI(t *testing.T) {
@@ -59,6 +98,21 @@ func TestCLI(t *testing.T) {
 		assertFinishCalledWith(t, game, "Cleo")
 	})
 
+	t.Run("start game with the names of 3 players", func(t *testing.T) {
+		game := &GameSpy{}
+
+		in := userSends("Chris, Cleo,Ruth", "Cleo wins")
+		cli := poker.NewCLI(in, dummyStdOut, game)
+
+		cli.PlayPoker()
+
+		assertGameStartedWith(t, game, 3)
+		if !reflect.DeepEqual(game.StartedWithNames, []string{"Chris", "Cleo", "Ruth"}) {
+			t.Errorf("got participants %q", game.StartedWithNames)
+		}
+		assertFinishCalledWith(t, game, "Cleo")
+	})
+
 	t.Run("Do not read beyond the first newline", func(t *testing.T) {
 		dummyAlerter := &poker.SpyBlindAlerter{}
 		dummyStore := &poker.StubPlayerStore{}
@@ -67,7 +121,7 @@ func TestCLI(t *testing.T) {
 			strings.NewReader("1\nChris win\nhello there"),
 		}
 
-		game := poker.NewTexasHoldem(dummyAlerter, dummyStore)
+		game := newSpiedTexasHoldem(dummyAlerter, dummyStore, standardBlinds)
 		cli := poker.NewCLI(in, dummyStdOut, game)
 		cli.PlayPoker()
 	})
@@ -87,6 +141,65 @@ func TestCLI(t *testing.T) {
 		assertMessagesSentToUser(t, stout, poker.PlayerPrompt, poker.BadPlayerInputErrMsg)
 	})
 
+	t.Run("it prints an error when fewer than 2 players are entered and does not start the game", func(t *testing.T) {
+		for _, players := range []string{"1", "0", "-3"} {
+			stout := &bytes.Buffer{}
+			game := &GameSpy{}
+
+			cli := poker.NewCLI(strings.NewReader(players+"\n"), stout, game)
+			cli.PlayPoker()
+
+			if game.StartCalled {
+				t.Errorf("game should not have started with %s players", players)
+			}
+
+			assertMessagesSentToUser(t, stout, poker.PlayerPrompt, poker.BadPlayerInputErrMsg)
+		}
+	})
+
+	t.Run("it prints an error when the game can't start and does not wait for a winner", func(t *testing.T) {
+		stout := &bytes.Buffer{}
+		in := userSends("3", "Chris wins")
+		game := &GameSpy{StartError: errors.New("invalid blind structure")}
+
+		cli := poker.NewCLI(in, stout, game)
+		cli.PlayPoker()
+
+		if game.FinishCalled {
+			t.Errorf("game should not have finished")
+		}
+
+		assertMessagesSentToUser(t, stout, poker.PlayerPrompt, "invalid blind structure")
+	})
+
+	t.Run("it controls the blinds until a winner is declared", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		game := &GameSpy{}
+
+		in := userSends("3", "pause", "resume", "next", "rewind", "Chris wins")
+		cli := poker.NewCLI(in, stdout, game)
+		cli.PlayPoker()
+
+		want := []string{poker.PauseCommand, poker.ResumeCommand, poker.NextCommand, poker.RewindCommand}
+		if !reflect.DeepEqual(game.Controls, want) {
+			t.Errorf("got controls %v want %v", game.Controls, want)
+		}
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, "Blinds paused\n", "Blinds resumed\n")
+		assertFinishCalledWith(t, game, "Chris")
+	})
+
+	t.Run("it prints why a blind command failed", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		game := &GameSpy{ControlError: poker.ErrNotPaused}
+
+		cli := poker.NewCLI(userSends("3", "resume", "Chris wins"), stdout, game)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.ErrNotPaused.Error()+"\n")
+		assertFinishCalledWith(t, game, "Chris")
+	})
+
 	t.Run("it prints an error when winner is declared incorrectly", func(t *testing.T) {
 		stout := &bytes.Buffer{}
 		in := strings.NewReader("2\nLloyd is a Killer\n")
@@ -106,11 +219,10 @@ func TestCLI(t *testing.T) {
 func assertFinishCalledWith(t *testing.T, game *GameSpy, wantedWinner string) {
 	t.Helper()
 
-	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.FinishedWith == wantedWinner
-	})
+	game.mu.Lock()
+	defer game.mu.Unlock()
 
-	if !passed {
+	if game.FinishedWith != wantedWinner {
 		t.Errorf("expected finish called with %q but got %q", wantedWinner, game.FinishedWith)
 	}
 }
@@ -118,11 +230,10 @@ func assertFinishCalledWith(t *testing.T, game *GameSpy, wantedWinner string) {
 func assertGameStartedWith(t *testing.T, game *GameSpy, numberOfPlayersWanted int) {
 	t.Helper()
 
-	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.StartedWith == numberOfPlayersWanted
-	})
+	game.mu.Lock()
+	defer game.mu.Unlock()
 
-	if !passed {
+	if game.StartedWith != numberOfPlayersWanted {
 		t.Errorf("expcted Start called with %d but got %d ", numberOfPlayersWanted, game.StartedWith)
 	}
 }
@@ -155,12 +266,26 @@ func (m failOnEndReader) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
-func retryUntil(d time.Duration, f func() bool) bool {
-	deadline := time.Now().Add(d)
-	for time.Now().Before(deadline) {
-		if f() {
-			return true
-		}
-	}
-	return false
+func TestPrintSeason(t *testing.T) {
+	now := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+
+	t.Run("prints the standings of the current season", func(t *testing.T) {
+		store := &poker.StubPlayerStore{Games: []poker.GameRecord{
+			{FinishedAt: now.AddDate(0, -1, 0), Winner: "Chris"},
+			{FinishedAt: now.Add(-time.Hour), Winner: "Cleo"},
+		}}
+		out := &bytes.Buffer{}
+
+		poker.PrintSeason(out, store, now)
+
+		assertMessagesSentToUser(t, out, "Season 2026-10\n", "1. Cleo 1\n")
+	})
+
+	t.Run("says when nobody has won yet", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		poker.PrintSeason(out, &poker.StubPlayerStore{}, now)
+
+		assertMessagesSentToUser(t, out, "Season 2026-10\n", "No wins yet this season\n")
+	})
 }
@@ -1,20 +1,41 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
-const dbFileName = "game.db.json"
-
 func main() {
-	fmt.Println("Let's play poker")
-	fmt.Println("Type {Name} wins to record a win")
+	showSeason := flag.Bool("season", false, "show the standings of the current season and exit")
+	config, err := poker.LoadConfig(flag.CommandLine, os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	store, closeFn, err := poker.FileSystemPlayerStoreFromFile(dbFileName)
+	if config.PrintConfig {
+		if err := config.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if args := flag.Args(); len(args) > 0 && args[0] == "token" {
+		tokenCommand(config, args[1:])
+		return
+	}
+
+	blinds, err := poker.LookupBlindStructure(config.Blinds)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store, closeFn, err := poker.OpenPlayerStore(config.Store, config.DB)
 
 	if err != nil {
 		log.Fatal(err)
@@ -22,8 +43,33 @@ func main() {
 
 	defer closeFn()
 
-	alerter := poker.BlindAlerterFunc(poker.StdOutAlerter)
-	game := poker.NewTexasHoldem(alerter, store)
+	if *showSeason {
+		poker.PrintSeason(os.Stdout, store, time.Now())
+		return
+	}
+
+	fmt.Println("Let's play poker")
+	fmt.Println("Type {Name} wins to record a win")
+	fmt.Println("Type pause, resume, next or rewind to control the blinds")
+
+	game := poker.NewTexasHoldem(clock.Real{}, store, blinds)
 	cli := poker.NewCLI(os.Stdin, os.Stdout, game)
 	cli.PlayPoker()
 }
+
+// tokenCommand manages the webserver's API tokens, in the file given by
+// -tokens or POKER_TOKENS.
+func tokenCommand(config poker.Config, args []string) {
+	if config.Tokens == "" {
+		log.Fatal("token commands need a tokens file, set with -tokens or " + poker.ConfigEnvPrefix + "TOKENS")
+	}
+
+	tokens, err := poker.TokenStoreFromFile(config.Tokens)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := poker.TokenCommand(os.Stdout, tokens, args, time.Now()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run migrates the stores, returning rather than exiting so the source is
+// always closed.
+func run() error {
+	fromKind := flag.String("from-store", poker.JSONStoreKind, "player store to read from, json, eventlog or sqlite")
+	fromPath := flag.String("from-db", "", "path of the store to read from, defaults to one per store")
+	toKind := flag.String("to-store", poker.SQLiteStoreKind, "player store to write to, json, eventlog or sqlite")
+	toPath := flag.String("to-db", "", "path of the store to write to, defaults to one per store")
+	dryRun := flag.Bool("dry-run", false, "report what would be migrated without writing anything")
+	flag.Parse()
+
+	same, err := sameFile(poker.StorePath(*fromKind, *fromPath), poker.StorePath(*toKind, *toPath))
+	if err != nil {
+		return err
+	}
+	if same {
+		return errors.New("source and target are the same store")
+	}
+
+	from, closeFrom, err := poker.OpenPlayerStore(*fromKind, *fromPath)
+	if err != nil {
+		return err
+	}
+	defer closeFrom()
+
+	report, err := poker.MigrateLeagueToFile(from, *toKind, *toPath, *dryRun)
+	if err != nil {
+		return fmt.Errorf("problem migrating league, %v", err)
+	}
+
+	if *dryRun {
+		fmt.Printf("would migrate %d players with %d wins, %d games and %d seasons from %s to %s\n", report.Players, report.Wins, report.Games, report.Seasons, *fromKind, *toKind)
+		return nil
+	}
+
+	fmt.Printf("migrated %d players with %d wins, %d games and %d seasons from %s to %s\n", report.Players, report.Wins, report.Games, report.Seasons, *fromKind, *toKind)
+	return nil
+}
+
+func sameFile(a, b string) (bool, error) {
+	absA, err := filepath.Abs(a)
+	if err != nil {
+		return false, fmt.Errorf("problem resolving %s, %v", a, err)
+	}
+
+	absB, err := filepath.Abs(b)
+	if err != nil {
+		return false, fmt.Errorf("problem resolving %s, %v", b, err)
+	}
+
+	return absA == absB, nil
+}
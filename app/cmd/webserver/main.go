@@ -1,29 +1,117 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
-const dbFileName = "game.db.json"
-
 func main() {
-	store, closeFn, err := poker.FileSystemPlayerStoreFromFile(dbFileName)
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
 
+// run serves until it's interrupted, returning rather than exiting so the
+// store is always closed.
+func run() error {
+	config, err := poker.LoadConfig(flag.CommandLine, os.Args[1:], os.Getenv)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	if config.PrintConfig {
+		return config.Print(os.Stdout)
+	}
+
+	if config.Tokens == "" && !config.Insecure {
+		return fmt.Errorf("no tokens file to authenticate with, set one with -tokens or %sTOKENS or run with -insecure", poker.ConfigEnvPrefix)
+	}
+
+	blinds, err := poker.LookupBlindStructure(config.Blinds)
+	if err != nil {
+		return err
+	}
+
+	fileStore, closeFn, err := poker.OpenPlayerStore(config.Store, config.DB)
+
+	if err != nil {
+		return err
 	}
 
 	defer closeFn()
 
-	game := poker.NewTexasHoldem(poker.BlindAlerterFunc(poker.Alerter), store)
+	// Games and the server both write through the notifying store, so live
+	// league watchers hear about every win.
+	store := poker.NewNotifyingStore(fileStore)
 
-	server, err := poker.NewPlayerServer(store, game)
+	games := poker.NewGameManager(func() poker.Game {
+		return poker.NewTexasHoldem(clock.Real{}, store, blinds)
+	})
+
+	server, err := poker.NewPlayerServer(store, games)
 	if err != nil {
-		log.Fatalf("problem creating player server %v", err)
+		return fmt.Errorf("problem creating player server %v", err)
+	}
+
+	if config.Assets != "" {
+		if err := server.UseAssetsDir(config.Assets); err != nil {
+			return err
+		}
+	}
+	server.UseWSBuffers(config.WSReadBuffer, config.WSWriteBuffer)
+
+	if config.Tokens != "" {
+		tokens, err := poker.TokenStoreFromFile(config.Tokens)
+		if err != nil {
+			return err
+		}
+		server.UseAuth(tokens)
+	} else {
+		log.Println("WARNING: running with -insecure and no tokens file, anyone can record wins, edit players and close seasons")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: config.Addr, Handler: server}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal stops us straight away.
+	stop()
+	log.Println("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	// The http.Server doesn't wait for websockets and would wait on event
+	// streams until the deadline, so the player server ends those first.
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println(err)
+	}
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		return fmt.Errorf("problem shutting down http server %v", err)
 	}
 
-	log.Fatal(http.ListenAndServe(":5000", server))
+	return nil
 }
@@ -0,0 +1,263 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnvPrefix starts the name of every environment variable that
+// configures the binaries, as in POKER_DB or POKER_SHUTDOWN_TIMEOUT.
+const ConfigEnvPrefix = "POKER_"
+
+// Config is what the webserver and cli are run with. The cli only uses the
+// store and blinds.
+type Config struct {
+	Store           string
+	DB              string
+	Blinds          string
+	Addr            string
+	Assets          string
+	Tokens          string
+	ShutdownTimeout time.Duration
+	WSReadBuffer    int
+	WSWriteBuffer   int
+
+	// PrintConfig asks for the configuration to be printed instead of run.
+	PrintConfig bool
+
+	// Insecure lets the webserver run without a tokens file. It's only ever
+	// a flag, so leaving authentication off is a choice made on each run.
+	Insecure bool
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Store:           JSONStoreKind,
+		Blinds:          StandardBlinds,
+		Addr:            ":5000",
+		ShutdownTimeout: 10 * time.Second,
+		WSReadBuffer:    DefaultWSBufferSize,
+		WSWriteBuffer:   DefaultWSBufferSize,
+	}
+}
+
+// configSetting is one setting of a Config. It's called key in config files,
+// with dashes in flags and upper case after ConfigEnvPrefix in the
+// environment.
+type configSetting struct {
+	key   string
+	usage string
+	get   func(c *Config) string
+	set   func(c *Config, value string) error
+}
+
+var configSettings = []configSetting{
+	{"store", "player store to use, json, eventlog or sqlite",
+		func(c *Config) string { return c.Store },
+		func(c *Config, v string) error { c.Store = v; return nil }},
+	{"db", "path of the player store file, defaults to one per store",
+		func(c *Config) string { return c.DB },
+		func(c *Config, v string) error { c.DB = v; return nil }},
+	{"blinds", "blind structure to play, a preset or a .json or .yaml file",
+		func(c *Config) string { return c.Blinds },
+		func(c *Config, v string) error { c.Blinds = v; return nil }},
+	{"addr", "address the webserver listens on",
+		func(c *Config) string { return c.Addr },
+		func(c *Config, v string) error { c.Addr = v; return nil }},
+	{"assets", "directory to serve the pages and static/ from instead of the built in ones, reloaded on every request",
+		func(c *Config) string { return c.Assets },
+		func(c *Config, v string) error { c.Assets = v; return nil }},
+	{"tokens", "path of the API tokens file the webserver authenticates with",
+		func(c *Config) string { return c.Tokens },
+		func(c *Config, v string) error { c.Tokens = v; return nil }},
+	{"shutdown_timeout", "how long to wait for games and requests to finish when shutting down",
+		func(c *Config) string { return c.ShutdownTimeout.String() },
+		func(c *Config, v string) (err error) { c.ShutdownTimeout, err = time.ParseDuration(v); return err }},
+	{"ws_read_buffer", "size in bytes of each websocket's read buffer",
+		func(c *Config) string { return strconv.Itoa(c.WSReadBuffer) },
+		func(c *Config, v string) (err error) { c.WSReadBuffer, err = strconv.Atoi(v); return err }},
+	{"ws_write_buffer", "size in bytes of each websocket's write buffer",
+		func(c *Config) string { return strconv.Itoa(c.WSWriteBuffer) },
+		func(c *Config, v string) (err error) { c.WSWriteBuffer, err = strconv.Atoi(v); return err }},
+}
+
+func (s configSetting) flag() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+func (s configSetting) env() string {
+	return ConfigEnvPrefix + strings.ToUpper(s.key)
+}
+
+// LoadConfig adds the shared settings to fs, parses args with it and merges
+// the defaults, a config file, the environment and then the flags, each
+// overriding the last. The config file is given by -config or POKER_CONFIG.
+func LoadConfig(fs *flag.FlagSet, args []string, getenv func(string) string) (Config, error) {
+	config := DefaultConfig()
+
+	flags := map[string]*string{}
+	for _, setting := range configSettings {
+		flags[setting.flag()] = fs.String(setting.flag(), setting.get(&config), setting.usage)
+	}
+	configPath := fs.String("config", "", "path of a .json or .yaml config file, also "+ConfigEnvPrefix+"CONFIG")
+	fs.BoolVar(&config.PrintConfig, "print-config", false, "print the configuration and exit")
+	fs.BoolVar(&config.Insecure, "insecure", false, "let the webserver run without a tokens file, so anyone can use it")
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if *configPath == "" {
+		*configPath = getenv(ConfigEnvPrefix + "CONFIG")
+	}
+	if *configPath != "" {
+		if err := config.readFile(*configPath); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, setting := range configSettings {
+		if value := getenv(setting.env()); value != "" {
+			if err := setting.set(&config, value); err != nil {
+				return Config{}, fmt.Errorf("problem reading %s, %v", setting.env(), err)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, setting := range configSettings {
+			if f.Name == setting.flag() && err == nil {
+				if setErr := setting.set(&config, *flags[f.Name]); setErr != nil {
+					err = fmt.Errorf("problem reading -%s, %v", f.Name, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, err
+	}
+
+	config.DB = StorePath(config.Store, config.DB)
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config, %v", err)
+	}
+
+	return config, nil
+}
+
+// readFile merges in the settings in a .json, .yaml or .yml file.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("problem reading config %s, %v", path, err)
+	}
+
+	var file map[string]interface{}
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return fmt.Errorf("unknown config format %q, expected .json, .yaml or .yml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("problem parsing config %s, %v", path, err)
+	}
+
+	for key, value := range file {
+		setting, ok := lookupConfigSetting(key)
+		if !ok {
+			return fmt.Errorf("problem parsing config %s, unknown setting %q", path, key)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if err := setting.set(c, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("problem parsing config %s, %s: %v", path, key, err)
+		}
+	}
+
+	return nil
+}
+
+func lookupConfigSetting(key string) (configSetting, bool) {
+	for _, setting := range configSettings {
+		if setting.key == key {
+			return setting, true
+		}
+	}
+
+	return configSetting{}, false
+}
+
+// Validate checks every setting makes sense, including that the blinds can
+// be found.
+func (c Config) Validate() error {
+	if _, ok := defaultStorePaths[c.Store]; !ok {
+		return fmt.Errorf("unknown store %q, expected %q, %q or %q", c.Store, JSONStoreKind, EventLogStoreKind, SQLiteStoreKind)
+	}
+
+	if c.DB == "" {
+		return errors.New("no db path")
+	}
+
+	if _, err := LookupBlindStructure(c.Blinds); err != nil {
+		return err
+	}
+
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("addr %q, %v", c.Addr, err)
+	}
+
+	if c.Assets != "" {
+		if info, err := os.Stat(c.Assets); err != nil || !info.IsDir() {
+			return fmt.Errorf("assets %q isn't a directory", c.Assets)
+		}
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		return fmt.Errorf("shutdown timeout %v must be positive", c.ShutdownTimeout)
+	}
+
+	if c.WSReadBuffer <= 0 || c.WSWriteBuffer <= 0 {
+		return fmt.Errorf("websocket buffers %d/%d must be positive", c.WSReadBuffer, c.WSWriteBuffer)
+	}
+
+	return nil
+}
+
+// Print writes the configuration as YAML that can be read back as a config
+// file.
+func (c Config) Print(w io.Writer) error {
+	var doc yaml.Node
+	doc.Kind = yaml.MappingNode
+	for _, setting := range configSettings {
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: setting.key},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: setting.get(&c)},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("problem printing config %v", err)
+	}
+
+	return encoder.Close()
+}
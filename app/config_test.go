@@ -0,0 +1,121 @@
+package poker_test
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		got := mustLoadConfig(t, nil, nil)
+
+		want := poker.DefaultConfig()
+		want.DB = "game.db.json"
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("flags override the environment, which overrides the file", func(t *testing.T) {
+		for _, file := range []struct{ name, contents string }{
+			{"poker.yaml", "store: sqlite\naddr: :6000\nws_read_buffer: 4096\nshutdown_timeout: 30s\n"},
+			{"poker.json", `{"store": "sqlite", "addr": ":6000", "ws_read_buffer": 4096, "shutdown_timeout": "30s"}`},
+		} {
+			t.Run(file.name, func(t *testing.T) {
+				path := writeConfigFile(t, file.name, file.contents)
+				env := map[string]string{"POKER_CONFIG": path, "POKER_ADDR": ":7000", "POKER_BLINDS": poker.TurboBlinds}
+
+				got := mustLoadConfig(t, []string{"-addr", "localhost:8000"}, env)
+
+				want := poker.DefaultConfig()
+				want.Store, want.DB = poker.SQLiteStoreKind, "game.db.sqlite"
+				want.Addr, want.Blinds = "localhost:8000", poker.TurboBlinds
+				want.WSReadBuffer, want.ShutdownTimeout = 4096, 30*time.Second
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("got %+v want %+v", got, want)
+				}
+			})
+		}
+	})
+
+	t.Run("running without authentication is only ever a flag", func(t *testing.T) {
+		if got := mustLoadConfig(t, []string{"-insecure"}, nil); !got.Insecure {
+			t.Error("expected -insecure to be set")
+		}
+
+		path := writeConfigFile(t, "insecure.yaml", "insecure: true\n")
+		if _, err := loadConfig([]string{"-config", path}, nil); err == nil {
+			t.Error("expected insecure to be refused in a config file")
+		}
+	})
+
+	t.Run("printed config reads back the same", func(t *testing.T) {
+		want := mustLoadConfig(t, []string{"-store", "eventlog", "-db", "league.jsonl", "-ws-write-buffer", "512"}, nil)
+
+		path := filepath.Join(t.TempDir(), "printed.yaml")
+		file, err := os.Create(path)
+		poker.AssertNoError(t, err)
+		poker.AssertNoError(t, want.Print(file))
+		file.Close()
+
+		if got := mustLoadConfig(t, []string{"-config", path}, nil); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("bad settings", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			args []string
+			env  map[string]string
+		}{
+			"unknown store":       {args: []string{"-store", "carrier-pigeon"}},
+			"unknown blinds":      {env: map[string]string{"POKER_BLINDS": "glacial"}},
+			"bad address":         {args: []string{"-addr", "5000"}},
+			"bad duration":        {env: map[string]string{"POKER_SHUTDOWN_TIMEOUT": "soon"}},
+			"no timeout":          {args: []string{"-shutdown-timeout", "0s"}},
+			"bad buffer":          {args: []string{"-ws-read-buffer", "-1"}},
+			"missing config file": {args: []string{"-config", "nope.yaml"}},
+			"unknown setting":     {env: map[string]string{"POKER_CONFIG": writeConfigFile(t, "extra.yaml", "colour: red\n")}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				if _, err := loadConfig(test.args, test.env); err == nil {
+					t.Error("expected an error but didn't get one")
+				}
+			})
+		}
+	})
+}
+
+func mustLoadConfig(t testing.TB, args []string, env map[string]string) poker.Config {
+	t.Helper()
+
+	config, err := loadConfig(args, env)
+	poker.AssertNoError(t, err)
+
+	return config
+}
+
+func loadConfig(args []string, env map[string]string) (poker.Config, error) {
+	fs := flag.NewFlagSet("poker", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	return poker.LoadConfig(fs, args, func(key string) string { return env[key] })
+}
+
+func writeConfigFile(t testing.TB, name, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0666); err != nil {
+		t.Fatalf("could not write config file %v", err)
+	}
+
+	return path
+}
@@ -0,0 +1,415 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const DefaultCompactionInterval = 1000
+
+const snapshotSuffix = ".snapshot"
+
+type WinEvent struct {
+	Seq    int
+	Player string
+	Time   time.Time
+	GameID string
+}
+
+// logEvent is one line of the log. Lines with a Game are finished games, or
+// posted wins, which count a win for their Player too, lines with a Season are
+// closed seasons, lines with a Change are edits to a player. Logs written
+// before games counted their own win also have lines that are just a win, and
+// game lines without a Player.
+type logEvent struct {
+	WinEvent
+	Game   *GameRecord   `json:",omitempty"`
+	Season *Season       `json:",omitempty"`
+	Change *playerChange `json:",omitempty"`
+}
+
+const (
+	setScoreChange     = "set_score"
+	deletePlayerChange = "delete"
+	movePlayerChange   = "move"
+)
+
+type playerChange struct {
+	Op   string
+	To   string `json:",omitempty"`
+	Wins int    `json:",omitempty"`
+}
+
+type eventLogSnapshot struct {
+	Seq     int
+	Offset  int64
+	League  League
+	Games   []GameRecord
+	Seasons []Season
+}
+
+// EventLogPlayerStore appends every win, game and season to a log instead of
+// rewriting the league. The league is rebuilt by replaying the log on top of
+// the latest snapshot, which is refreshed every compactEvery events. The log
+// itself is never truncated so it stays a full audit trail of wins.
+type EventLogPlayerStore struct {
+	mu           sync.RWMutex
+	events       *os.File
+	snapshot     *json.Encoder
+	league       League
+	games        []GameRecord
+	seasons      []Season
+	seq          int
+	offset       int64
+	sinceCompact int
+	compactEvery int
+}
+
+func (e *EventLogPlayerStore) GetPlayerScore(name string) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	player := e.league.Find(name)
+
+	if player != nil {
+		return player.Wins
+	}
+
+	return 0
+}
+
+func (e *EventLogPlayerStore) RecordWin(name string) error {
+	return e.RecordGame(postedWin(name, time.Now()))
+}
+
+func (e *EventLogPlayerStore) RecordGame(game GameRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event := logEvent{WinEvent: WinEvent{Player: game.Winner, GameID: game.ID}, Game: &game}
+
+	if err := e.append(event); err != nil {
+		return fmt.Errorf("problem saving game %s, %v", game.ID, err)
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) GetGames() []GameRecord {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return newestFirst(e.games)
+}
+
+func (e *EventLogPlayerStore) ArchiveSeason(season Season) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := closedSeason(e.seasons, season.ID); ok {
+		return fmt.Errorf("%w: %s", ErrSeasonClosed, season.ID)
+	}
+
+	if err := e.append(logEvent{Season: &season}); err != nil {
+		return fmt.Errorf("problem saving season %s, %v", season.ID, err)
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) GetSeasons() []Season {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return append([]Season{}, e.seasons...)
+}
+
+func (e *EventLogPlayerStore) SetPlayerScore(name string, wins int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event := logEvent{WinEvent: WinEvent{Player: name}, Change: &playerChange{Op: setScoreChange, Wins: wins}}
+
+	if err := e.append(event); err != nil {
+		return fmt.Errorf("problem saving score for %s, %v", name, err)
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) DeletePlayer(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !hasPlayer(e.league, e.games, name) {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, name)
+	}
+
+	event := logEvent{WinEvent: WinEvent{Player: name}, Change: &playerChange{Op: deletePlayerChange}}
+
+	if err := e.append(event); err != nil {
+		return fmt.Errorf("problem deleting %s, %v", name, err)
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) RenamePlayer(from, to string) error {
+	return e.movePlayer(from, to, false)
+}
+
+func (e *EventLogPlayerStore) MergePlayers(from, into string) error {
+	return e.movePlayer(from, into, true)
+}
+
+func (e *EventLogPlayerStore) movePlayer(from, to string, merge bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := checkRename(e.league, e.games, from, to, merge); err != nil {
+		return err
+	}
+
+	event := logEvent{WinEvent: WinEvent{Player: from}, Change: &playerChange{Op: movePlayerChange, To: to}}
+
+	if err := e.append(event); err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) append(event logEvent) error {
+	event.Seq = e.seq + 1
+	event.Time = time.Now().UTC()
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := e.events.WriteAt(line, e.offset); err != nil {
+		return err
+	}
+
+	if err := e.events.Sync(); err != nil {
+		return err
+	}
+
+	e.offset += int64(len(line))
+	e.apply(event)
+
+	e.sinceCompact++
+	if e.sinceCompact >= e.compactEvery {
+		// the event is already durable in the log, a failed compaction is
+		// retried on the next event
+		if err := e.compact(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	return nil
+}
+
+func (e *EventLogPlayerStore) apply(event logEvent) {
+	if event.Seq <= e.seq {
+		return
+	}
+
+	switch {
+	case event.Game != nil:
+		e.games = append(e.games, *event.Game)
+		if event.Player != "" {
+			e.league = e.league.withWin(event.Player)
+		}
+	case event.Season != nil:
+		e.seasons = append(e.seasons, *event.Season)
+	case event.Change != nil:
+		e.applyChange(event.Player, *event.Change)
+	default:
+		e.league = e.league.withWin(event.Player)
+	}
+
+	e.seq = event.Seq
+}
+
+func (e *EventLogPlayerStore) GetLeague() League {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.league.sortedByWins()
+}
+
+func (e *EventLogPlayerStore) applyChange(name string, change playerChange) {
+	switch change.Op {
+	case setScoreChange:
+		e.league = e.league.withScore(name, change.Wins)
+	case deletePlayerChange:
+		e.league = e.league.without(name)
+		e.games = renamedInGames(e.games, name, "")
+	case movePlayerChange:
+		e.league = e.league.merged(name, change.To)
+		e.games = renamedInGames(e.games, name, change.To)
+	}
+}
+
+// WinEvents returns every win ever recorded, oldest first.
+func (e *EventLogPlayerStore) WinEvents() ([]WinEvent, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var events []WinEvent
+	_, _, err := readLogEvents(io.NewSectionReader(e.events, 0, e.offset), func(event logEvent) {
+		isWin := event.Season == nil && event.Change == nil && event.Player != ""
+		if isWin {
+			events = append(events, event.WinEvent)
+		}
+	})
+
+	return events, err
+}
+
+// Compact writes the current league and games to the snapshot so the next
+// start only has to replay events recorded after it.
+func (e *EventLogPlayerStore) Compact() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.compact()
+}
+
+func (e *EventLogPlayerStore) compact() error {
+	snapshot := eventLogSnapshot{Seq: e.seq, Offset: e.offset, League: e.league, Games: e.games, Seasons: e.seasons}
+
+	if err := e.snapshot.Encode(snapshot); err != nil {
+		return fmt.Errorf("problem compacting event log, %v", err)
+	}
+
+	e.sinceCompact = 0
+	return nil
+}
+
+func NewEventLogPlayerStore(events, snapshot *os.File, compactEvery int) (*EventLogPlayerStore, error) {
+	if compactEvery < 1 {
+		return nil, fmt.Errorf("compaction interval must be at least 1, got %d", compactEvery)
+	}
+
+	if err := RecoverTape(snapshot); err != nil {
+		return nil, fmt.Errorf("problem recovering snapshot file, %v", err)
+	}
+
+	last, err := loadEventLogSnapshot(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("problem loading snapshot from file %s, %v", snapshot.Name(), err)
+	}
+
+	store := &EventLogPlayerStore{
+		events:       events,
+		snapshot:     json.NewEncoder(&Tape{snapshot}),
+		league:       last.League,
+		games:        last.Games,
+		seasons:      last.Seasons,
+		seq:          last.Seq,
+		offset:       last.Offset,
+		compactEvery: compactEvery,
+	}
+
+	if store.league == nil {
+		store.league = League{}
+	}
+
+	replayed, end, err := readLogEvents(io.NewSectionReader(events, last.Offset, 1<<62), store.apply)
+	if err != nil {
+		return nil, fmt.Errorf("problem replaying event log %s, %v", events.Name(), err)
+	}
+
+	store.offset = last.Offset + end
+	store.sinceCompact = replayed
+
+	// drop a partially written last event so new wins start on a fresh line
+	if err := events.Truncate(store.offset); err != nil {
+		return nil, fmt.Errorf("problem truncating event log %s, %v", events.Name(), err)
+	}
+
+	return store, nil
+}
+
+func EventLogPlayerStoreFromFile(path string) (*EventLogPlayerStore, func(), error) {
+	events, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+
+	snapshotPath := path + snapshotSuffix
+	snapshot, err := os.OpenFile(snapshotPath, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		events.Close()
+		return nil, nil, fmt.Errorf("problem opening %s %v", snapshotPath, err)
+	}
+
+	closeFunc := func() {
+		events.Close()
+		snapshot.Close()
+	}
+
+	store, err := NewEventLogPlayerStore(events, snapshot, DefaultCompactionInterval)
+	if err != nil {
+		closeFunc()
+		return nil, nil, fmt.Errorf("problem creating event log store, %v", err)
+	}
+
+	return store, closeFunc, nil
+}
+
+func loadEventLogSnapshot(file *os.File) (eventLogSnapshot, error) {
+	var snapshot eventLogSnapshot
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return snapshot, err
+	}
+
+	contents, err := io.ReadAll(file)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if len(bytes.TrimSpace(contents)) == 0 {
+		return snapshot, nil
+	}
+
+	err = json.Unmarshal(contents, &snapshot)
+	return snapshot, err
+}
+
+// readLogEvents calls found for every complete event in r and returns how
+// many there were and the offset just past the last one.
+func readLogEvents(r io.Reader, found func(logEvent)) (count int, end int64, err error) {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return count, end, nil
+		}
+		if err != nil {
+			return count, end, err
+		}
+
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return count, end, fmt.Errorf("problem parsing event at offset %d, %v", end, err)
+		}
+
+		found(event)
+		count++
+		end += int64(len(line))
+	}
+}
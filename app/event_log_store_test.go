@@ -0,0 +1,148 @@
+package poker_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestEventLogStore(t *testing.T) {
+	events, cleanEvents := createTempFile(t, "")
+	defer cleanEvents()
+	snapshot, cleanSnapshot := createTempFile(t, "")
+	defer cleanSnapshot()
+
+	store, err := poker.NewEventLogPlayerStore(events, snapshot, 3)
+	poker.AssertNoError(t, err)
+
+	recordWins(t, store, "Chris", "Cleo", "Chris", "Chris", "Pepper")
+
+	t.Run("league sorted by scores", func(t *testing.T) {
+		want := []poker.Player{
+			{"Chris", 3},
+			{"Cleo", 1},
+			{"Pepper", 1},
+		}
+
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("get player score", func(t *testing.T) {
+		assertScoreEquals(t, store.GetPlayerScore("Chris"), 3)
+		assertScoreEquals(t, store.GetPlayerScore("Nobody"), 0)
+	})
+
+	t.Run("every win is kept in the log", func(t *testing.T) {
+		got, err := store.WinEvents()
+		poker.AssertNoError(t, err)
+
+		want := []string{"Chris", "Cleo", "Chris", "Chris", "Pepper"}
+		if len(got) != len(want) {
+			t.Fatalf("got %d events want %d", len(got), len(want))
+		}
+
+		for i, event := range got {
+			if event.Seq != i+1 || event.Player != want[i] || event.Time.IsZero() || event.GameID == "" {
+				t.Errorf("unexpected event %d %+v", i, event)
+			}
+		}
+	})
+
+	t.Run("league is rebuilt from snapshot and log on restart", func(t *testing.T) {
+		events.Seek(0, 0)
+		snapshot.Seek(0, 0)
+
+		reopened, err := poker.NewEventLogPlayerStore(events, snapshot, 3)
+		poker.AssertNoError(t, err)
+
+		poker.AssertLeague(t, reopened.GetLeague(), store.GetLeague())
+	})
+}
+
+func TestEventLogStoreRecovery(t *testing.T) {
+	t.Run("ignores a partially written last event", func(t *testing.T) {
+		events, cleanEvents := createTempFile(t, `{"Seq":1,"Player":"Chris"}
+{"Seq":2,"Player":"Cl`)
+		defer cleanEvents()
+		snapshot, cleanSnapshot := createTempFile(t, "")
+		defer cleanSnapshot()
+
+		store, err := poker.NewEventLogPlayerStore(events, snapshot, 10)
+		poker.AssertNoError(t, err)
+
+		recordWins(t, store, "Cleo")
+
+		poker.AssertLeague(t, store.GetLeague(), []poker.Player{{"Chris", 1}, {"Cleo", 1}})
+		assertFileContents(t, events, `{"Seq":1,"Player":"Chris"}
+{"Seq":2,"Player":"Cleo"`)
+	})
+
+	t.Run("does not count events already in the snapshot twice", func(t *testing.T) {
+		events, cleanEvents := createTempFile(t, `{"Seq":1,"Player":"Chris"}
+{"Seq":2,"Player":"Chris"}
+`)
+		defer cleanEvents()
+		snapshot, cleanSnapshot := createTempFile(t, `{"Seq":2,"Offset":0,"League":[{"Name":"Chris","Wins":2}]}`)
+		defer cleanSnapshot()
+
+		store, err := poker.NewEventLogPlayerStore(events, snapshot, 10)
+		poker.AssertNoError(t, err)
+
+		assertScoreEquals(t, store.GetPlayerScore("Chris"), 2)
+	})
+
+	t.Run("does not count wins of games from older logs twice", func(t *testing.T) {
+		events, cleanEvents := createTempFile(t, `{"Seq":1,"Player":"Chris","GameID":"x"}
+{"Seq":2,"GameID":"a","Game":{"ID":"a","Winner":"Chris"}}
+{"Seq":3,"Player":"Cleo","GameID":"b","Game":{"ID":"b","Winner":"Cleo"}}
+`)
+		defer cleanEvents()
+		snapshot, cleanSnapshot := createTempFile(t, "")
+		defer cleanSnapshot()
+
+		store, err := poker.NewEventLogPlayerStore(events, snapshot, 10)
+		poker.AssertNoError(t, err)
+
+		poker.AssertLeague(t, store.GetLeague(), []poker.Player{{"Chris", 1}, {"Cleo", 1}})
+
+		wins, err := store.WinEvents()
+		poker.AssertNoError(t, err)
+		if len(wins) != 2 {
+			t.Errorf("got wins %+v want one each for Chris and Cleo", wins)
+		}
+	})
+
+	t.Run("rejects a compaction interval below one", func(t *testing.T) {
+		events, cleanEvents := createTempFile(t, "")
+		defer cleanEvents()
+		snapshot, cleanSnapshot := createTempFile(t, "")
+		defer cleanSnapshot()
+
+		_, err := poker.NewEventLogPlayerStore(events, snapshot, 0)
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func recordWins(t testing.TB, store poker.PlayerStore, winners ...string) {
+	t.Helper()
+
+	for _, winner := range winners {
+		poker.AssertNoError(t, store.RecordWin(winner))
+	}
+}
+
+func assertFileContents(t testing.TB, file *os.File, wantPrefix string) {
+	t.Helper()
+
+	file.Seek(0, 0)
+	contents, _ := io.ReadAll(file)
+
+	if !strings.HasPrefix(string(contents), wantPrefix) {
+		t.Errorf("got file contents %q want them to start with %q", contents, wantPrefix)
+	}
+}
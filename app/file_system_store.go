@@ -1,19 +1,35 @@
 package poker
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"sort"
+	"sync"
+	"time"
 )
 
 type FileSystemPlayerStore struct {
+	mu       sync.RWMutex
 	database *json.Encoder
 	league   League
+	games    []GameRecord
+	seasons  []Season
+}
+
+// playerDB is the layout of the database file. Files written before games
+// were recorded hold just the league array, which is still read.
+type playerDB struct {
+	League  League
+	Games   []GameRecord
+	Seasons []Season
 }
 
 func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	player := f.league.Find(name)
 
 	if player != nil {
@@ -23,24 +39,145 @@ func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
 	return 0
 }
 
-func (f *FileSystemPlayerStore) RecordWin(name string) {
-	player := f.league.Find(name)
+func (f *FileSystemPlayerStore) RecordWin(name string) error {
+	return f.RecordGame(postedWin(name, time.Now()))
+}
 
-	if player != nil {
-		player.Wins++
-	} else {
-		f.league = append(f.league, Player{name, 1})
+func (f *FileSystemPlayerStore) ImportLeague(imported League) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	league := f.league
+	for _, player := range imported {
+		league = league.withScore(player.Name, player.Wins)
 	}
 
-	f.database.Encode(f.league)
+	if err := f.database.Encode(playerDB{league, f.games, f.seasons}); err != nil {
+		return fmt.Errorf("problem saving imported league, %v", err)
+	}
+
+	f.league = league
+	return nil
 }
 
 func (f *FileSystemPlayerStore) GetLeague() League {
-	sort.Slice(f.league, func(i, j int) bool {
-		return f.league[i].Wins > f.league[j].Wins
-	})
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.league.sortedByWins()
+}
+
+func (f *FileSystemPlayerStore) RecordGame(game GameRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	league := f.league
+	if game.Winner != "" {
+		league = append(League{}, f.league...).withWin(game.Winner)
+	}
+	games := append(append([]GameRecord{}, f.games...), game)
+
+	if err := f.database.Encode(playerDB{league, games, f.seasons}); err != nil {
+		return fmt.Errorf("problem saving game %s, %v", game.ID, err)
+	}
+
+	f.league = league
+	f.games = games
+	return nil
+}
+
+func (f *FileSystemPlayerStore) GetGames() []GameRecord {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 
-	return f.league
+	return newestFirst(f.games)
+}
+
+func (f *FileSystemPlayerStore) ArchiveSeason(season Season) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := closedSeason(f.seasons, season.ID); ok {
+		return fmt.Errorf("%w: %s", ErrSeasonClosed, season.ID)
+	}
+
+	seasons := append(append([]Season{}, f.seasons...), season)
+
+	if err := f.database.Encode(playerDB{f.league, f.games, seasons}); err != nil {
+		return fmt.Errorf("problem saving season %s, %v", season.ID, err)
+	}
+
+	f.seasons = seasons
+	return nil
+}
+
+func (f *FileSystemPlayerStore) GetSeasons() []Season {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return append([]Season{}, f.seasons...)
+}
+
+func (f *FileSystemPlayerStore) SetPlayerScore(name string, wins int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	league := f.league.withScore(name, wins)
+
+	if err := f.database.Encode(playerDB{league, f.games, f.seasons}); err != nil {
+		return fmt.Errorf("problem saving score for %s, %v", name, err)
+	}
+
+	f.league = league
+	return nil
+}
+
+func (f *FileSystemPlayerStore) DeletePlayer(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !hasPlayer(f.league, f.games, name) {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, name)
+	}
+
+	league := f.league.without(name)
+	games := renamedInGames(f.games, name, "")
+
+	if err := f.database.Encode(playerDB{league, games, f.seasons}); err != nil {
+		return fmt.Errorf("problem deleting %s, %v", name, err)
+	}
+
+	f.league = league
+	f.games = games
+	return nil
+}
+
+func (f *FileSystemPlayerStore) RenamePlayer(from, to string) error {
+	return f.movePlayer(from, to, false)
+}
+
+func (f *FileSystemPlayerStore) MergePlayers(from, into string) error {
+	return f.movePlayer(from, into, true)
+}
+
+func (f *FileSystemPlayerStore) movePlayer(from, to string, merge bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := checkRename(f.league, f.games, from, to, merge); err != nil {
+		return err
+	}
+
+	league := f.league.merged(from, to)
+	games := renamedInGames(f.games, from, to)
+
+	if err := f.database.Encode(playerDB{league, games, f.seasons}); err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+
+	f.league = league
+	f.games = games
+	return nil
 }
 
 func initializePlayerDBFile(file *os.File) error {
@@ -60,13 +197,17 @@ func initializePlayerDBFile(file *os.File) error {
 }
 
 func NewFileSystemStore(file *os.File) (*FileSystemPlayerStore, error) {
+	if err := RecoverTape(file); err != nil {
+		return nil, fmt.Errorf("problem recovering player db file, %v", err)
+	}
+
 	err := initializePlayerDBFile(file)
 
 	if err != nil {
 		return nil, fmt.Errorf("problem initializin player db file, %v", err)
 	}
 
-	league, err := NewLeague(file)
+	db, err := newPlayerDB(file)
 
 	if err != nil {
 		return nil, fmt.Errorf("problem loading player store from file %s, %v", file.Name(), err)
@@ -74,14 +215,36 @@ func NewFileSystemStore(file *os.File) (*FileSystemPlayerStore, error) {
 
 	return &FileSystemPlayerStore{
 		database: json.NewEncoder(&Tape{file}),
-		league:   league,
+		league:   db.League,
+		games:    db.Games,
+		seasons:  db.Seasons,
 	}, nil
 }
 
+func newPlayerDB(reader io.Reader) (playerDB, error) {
+	var db playerDB
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return db, err
+	}
+
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '[' {
+		db.League, err = NewLeague(bytes.NewReader(trimmed))
+		return db, err
+	}
+
+	if err := json.Unmarshal(contents, &db); err != nil {
+		return db, fmt.Errorf("problem parsing player db, %v", err)
+	}
+
+	return db, nil
+}
+
 func FileSystemPlayerStoreFromFile(path string) (*FileSystemPlayerStore, func(), error) {
 	db, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
 	if err != nil {
-		log.Fatalf("problem opening %s %v", path, err)
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
 	}
 
 	closeFunc := func() {
@@ -90,7 +253,8 @@ func FileSystemPlayerStoreFromFile(path string) (*FileSystemPlayerStore, func(),
 
 	store, err := NewFileSystemStore(db)
 	if err != nil {
-		log.Fatalf("problem creating file system store, %v", err)
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating file system store, %v", err)
 	}
 
 	return store, closeFunc, nil
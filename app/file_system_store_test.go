@@ -1,7 +1,9 @@
 package poker_test
 
 import (
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
@@ -39,7 +41,7 @@ func TestFileSystemStore(t *testing.T) {
 
 	t.Run("store wins for existing players", func(t *testing.T) {
 		winner_name := "Chris"
-		store.RecordWin(winner_name)
+		poker.AssertNoError(t, store.RecordWin(winner_name))
 		got := store.GetPlayerScore(winner_name)
 		want := 34
 		assertScoreEquals(t, got, want)
@@ -47,7 +49,7 @@ func TestFileSystemStore(t *testing.T) {
 
 	t.Run("store wins for new players", func(t *testing.T) {
 		winner_name := "Pepper"
-		store.RecordWin(winner_name)
+		poker.AssertNoError(t, store.RecordWin(winner_name))
 
 		got := store.GetPlayerScore(winner_name)
 		want := 1
@@ -89,3 +91,70 @@ func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
 
 	return tmpfile, removeFIleFn
 }
+
+func TestFileSystemStoreConcurrentAccess(t *testing.T) {
+	database, cleanDatabaseFn := createTempFile(t, "[]")
+	defer cleanDatabaseFn()
+
+	store, err := poker.NewFileSystemStore(database)
+	poker.AssertNoError(t, err)
+
+	players := []string{"Chris", "Cleo", "Pepper"}
+	winsEach := 50
+
+	var wg sync.WaitGroup
+	for _, player := range players {
+		for i := 0; i < winsEach; i++ {
+			wg.Add(3)
+			go func(player string) {
+				defer wg.Done()
+				if err := store.RecordWin(player); err != nil {
+					t.Errorf("didn't expect an error but got one, %v", err)
+				}
+			}(player)
+			go func(player string) {
+				defer wg.Done()
+				store.GetPlayerScore(player)
+			}(player)
+			go func() {
+				defer wg.Done()
+				league := store.GetLeague()
+				if len(league) > 0 {
+					league[0].Wins = -1
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, player := range players {
+		assertScoreEquals(t, store.GetPlayerScore(player), winsEach)
+	}
+}
+
+func TestRecordingWinsOverConcurrentRequests(t *testing.T) {
+	database, cleanDatabaseFn := createTempFile(t, "[]")
+	defer cleanDatabaseFn()
+
+	store, err := poker.NewFileSystemStore(database)
+	poker.AssertNoError(t, err)
+
+	server := mustCreatePlayerServer(t, store, dummyGame)
+	wantedWins := 100
+
+	var wg sync.WaitGroup
+	wg.Add(wantedWins * 2)
+	for i := 0; i < wantedWins; i++ {
+		go func() {
+			defer wg.Done()
+			server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest("Pepper"))
+		}()
+		go func() {
+			defer wg.Done()
+			server.ServeHTTP(httptest.NewRecorder(), newLeagueRequest())
+		}()
+	}
+	wg.Wait()
+
+	assertScoreEquals(t, store.GetPlayerScore("Pepper"), wantedWins)
+}
@@ -1,8 +1,154 @@
 package poker
 
-import "io"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
 
 type Game interface {
-	Start(numberOfPlayers int, alertsDestionation io.Writer)
-	Finish(winner string)
+	Start(ctx context.Context, setup GameSetup, alertsDestionation io.Writer) error
+	Finish(winner string) error
+	BlindControls
+}
+
+// BlindControls move the blind clock of the game in progress.
+type BlindControls interface {
+	Pause() error
+	Resume() error
+	NextLevel() error
+	PreviousLevel() error
+}
+
+// Commands players can send during a game to control the blinds.
+const (
+	PauseCommand  = "pause"
+	ResumeCommand = "resume"
+	NextCommand   = "next"
+	RewindCommand = "rewind"
+)
+
+// blindCommand returns the control named by input and what to say once it's
+// done, or false when input isn't a command.
+func blindCommand(controls BlindControls, input string) (func() error, string, bool) {
+	switch strings.TrimSpace(input) {
+	case PauseCommand:
+		return controls.Pause, "Blinds paused\n", true
+	case ResumeCommand:
+		return controls.Resume, "Blinds resumed\n", true
+	case NextCommand:
+		return controls.NextLevel, "", true
+	case RewindCommand:
+		return controls.PreviousLevel, "", true
+	}
+
+	return nil, "", false
+}
+
+// GameSetup is who is playing a game and how. Participants is empty when only
+// the number of players is known, and a nil Blinds uses the game's default. An
+// empty ID gets the game's record a new one.
+type GameSetup struct {
+	ID              string
+	NumberOfPlayers int
+	Participants    []string
+	Blinds          *BlindStructure
+}
+
+// GameRecord is the history of a single finished game. Participants is empty
+// when the game was started with just a number of players. Games only finish
+// with a winner, so the Winner is the one place recorded, and games themselves
+// are kept in the order they finished.
+type GameRecord struct {
+	ID              string
+	StartedAt       time.Time
+	FinishedAt      time.Time
+	NumberOfPlayers int
+	Participants    []string
+	Winner          string
+	BlindLevel      int
+	Blind           int
+}
+
+// postedWin is the record of a win posted on its own rather than at the end of
+// a game, so it counts towards the season it was won in. Nobody is known to
+// have played it, which keeps it out of stats and ratings.
+func postedWin(name string, at time.Time) GameRecord {
+	return GameRecord{ID: newGameID(), StartedAt: at.UTC(), FinishedAt: at.UTC(), Winner: name}
+}
+
+// isPostedWin is true for the record of a win posted on its own, including
+// those recorded as a game of their own before wins were posted this way.
+func (g GameRecord) isPostedWin() bool {
+	return g.NumberOfPlayers == 0 && len(g.Participants) == 0
+}
+
+// playedGames leaves out the posted wins among games, which weren't games
+// anyone played.
+func playedGames(games []GameRecord) []GameRecord {
+	played := make([]GameRecord, 0, len(games))
+	for _, game := range games {
+		if !game.isPostedWin() {
+			played = append(played, game)
+		}
+	}
+
+	return played
+}
+
+func newGameID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func newestFirst(games []GameRecord) []GameRecord {
+	sorted := make([]GameRecord, len(games))
+	for i, game := range games {
+		sorted[len(games)-1-i] = game
+	}
+
+	return sorted
+}
+
+func inFinishingOrder(games []GameRecord) []GameRecord {
+	sorted := append([]GameRecord{}, games...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].FinishedAt.Before(sorted[j].FinishedAt)
+	})
+
+	return sorted
+}
+
+// renamedInGames replaces from with to in the winners and participants of
+// games. Renaming to "" removes the player, leaving games they won without a
+// winner.
+func renamedInGames(games []GameRecord, from, to string) []GameRecord {
+	renamed := make([]GameRecord, len(games))
+
+	for i, game := range games {
+		if game.Winner == from {
+			game.Winner = to
+		}
+
+		var participants []string
+		for _, name := range game.Participants {
+			if name == from {
+				name = to
+			}
+			if name != "" {
+				participants = append(participants, name)
+			}
+		}
+		game.Participants = participants
+
+		renamed[i] = game
+	}
+
+	return renamed
 }
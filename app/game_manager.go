@@ -0,0 +1,357 @@
+package poker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
+)
+
+var (
+	ErrTableNotFound = errors.New("table not found")
+	ErrTableTaken    = errors.New("table already has players")
+)
+
+// tableClientBuffer is how many messages a client of a table can fall behind
+// before it's dropped.
+const tableClientBuffer = 32
+
+// DefaultReconnectGrace is how long a table waits for its players to come
+// back after their connection drops.
+const DefaultReconnectGrace = 2 * time.Minute
+
+// DefaultIdleTimeout is how long a table waits for players to sit at it
+// after it's opened.
+const DefaultIdleTimeout = 10 * time.Minute
+
+// Table is a game that is being played or waiting for its players. Its ID is
+// also the ID of the game's record once it finishes.
+type Table struct {
+	ID              string
+	CreatedAt       time.Time
+	Playing         bool
+	NumberOfPlayers int
+	Participants    []string
+	Spectators      int
+}
+
+// GameManager runs an independent game at each table, so tables can be played
+// at the same time.
+type GameManager struct {
+	newGame        func() Game
+	reconnectGrace time.Duration
+	idleTimeout    time.Duration
+	clock          clock.Clock
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+type table struct {
+	Table
+	game Game
+	hub  *Hub[WSMessage]
+
+	// The game is played in ctx, which outlives any one connection so
+	// players can reconnect to it.
+	ctx    context.Context
+	cancel context.CancelFunc
+	token  string
+
+	// closing closes the table when nobody is sitting at it in time.
+	closing clock.Timer
+
+	mu           sync.Mutex
+	events       []WSMessage
+	started      *WSMessage
+	latestBlinds *WSMessage
+}
+
+// publish sends msg to everyone at the table as its next event, remembering
+// it for those who join later or come back.
+func (t *table) publish(msg WSMessage) {
+	msg.Version = WSProtocolVersion
+
+	// Publishing while locked keeps events in order and lets subscribe
+	// snapshot what happened without missing or repeating anything.
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	msg.EventID = len(t.events) + 1
+	t.events = append(t.events, msg)
+
+	switch msg.Type {
+	case GameStartedMessage:
+		t.started = &msg
+	case BlindUpdateMessage:
+		t.latestBlinds = &msg
+	}
+
+	t.hub.Publish(msg)
+}
+
+// subscribe joins the table, returning the start and latest blinds of the
+// game so far and a channel of what happens next.
+func (t *table) subscribe() ([]WSMessage, <-chan WSMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var happened []WSMessage
+	for _, msg := range []*WSMessage{t.started, t.latestBlinds} {
+		if msg != nil {
+			happened = append(happened, *msg)
+		}
+	}
+
+	return happened, t.hub.Subscribe()
+}
+
+// subscribeSince joins the table, returning every event after lastEventID
+// and a channel of what happens next.
+func (t *table) subscribeSince(lastEventID int) ([]WSMessage, <-chan WSMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if lastEventID < 0 || lastEventID > len(t.events) {
+		lastEventID = len(t.events)
+	}
+	happened := append([]WSMessage(nil), t.events[lastEventID:]...)
+
+	return happened, t.hub.Subscribe()
+}
+
+func (t *table) hasStarted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.started != nil
+}
+
+// WriteBlindUpdate announces the table's blinds to everyone at it.
+func (t *table) WriteBlindUpdate(update BlindUpdate) error {
+	t.publish(WSMessage{Type: BlindUpdateMessage, Blind: &update})
+	return nil
+}
+
+func (t *table) Write(p []byte) (int, error) {
+	return 0, errors.New("tables only take blind updates")
+}
+
+// NewGameManager creates a manager that plays each table with a game from
+// newGame.
+func NewGameManager(newGame func() Game) *GameManager {
+	return &GameManager{
+		newGame:        newGame,
+		reconnectGrace: DefaultReconnectGrace,
+		idleTimeout:    DefaultIdleTimeout,
+		clock:          clock.Real{},
+		tables:         map[string]*table{},
+	}
+}
+
+// UseClock changes the clock tables are opened and abandoned by.
+func (m *GameManager) UseClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock = c
+}
+
+// UseReconnectGrace sets how long a table waits for its players to reconnect
+// before abandoning its game.
+func (m *GameManager) UseReconnectGrace(grace time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnectGrace = grace
+}
+
+// UseIdleTimeout sets how long a table waits for players to sit at it before
+// it's closed.
+func (m *GameManager) UseIdleTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.idleTimeout = timeout
+}
+
+// Open sets up a new table for players to sit at, closing it again if nobody
+// sits within the idle timeout.
+func (m *GameManager) Open() Table {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &table{
+		Table:  Table{ID: newGameID()},
+		game:   m.newGame(),
+		hub:    NewHub[WSMessage](tableClientBuffer),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t.CreatedAt = m.clock.Now().UTC()
+	m.tables[t.ID] = t
+	m.closeUnlessSeated(t, m.idleTimeout)
+	return t.Table
+}
+
+// Tables returns the open tables, oldest first.
+func (m *GameManager) Tables() []Table {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tables := make([]Table, 0, len(m.tables))
+	for _, t := range m.tables {
+		tables = append(tables, t.Table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].CreatedAt.Before(tables[j].CreatedAt)
+	})
+
+	return tables
+}
+
+func (m *GameManager) Table(id string) (Table, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[id]
+	if !ok {
+		return Table{}, false
+	}
+
+	return t.Table, true
+}
+
+// Close tears down a table once its game is over or abandoned, letting its
+// clients go.
+func (m *GameManager) Close(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tables[id]; ok {
+		m.close(t)
+	}
+}
+
+// Shutdown tells everyone at every table that the server is going away, then
+// tears the tables down, abandoning the games still being played.
+func (m *GameManager) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range m.tables {
+		t.publish(WSMessage{Type: ServerClosingMessage})
+		m.close(t)
+	}
+}
+
+func (m *GameManager) close(t *table) {
+	if t.closing != nil {
+		t.closing.Stop()
+	}
+	t.cancel()
+	t.hub.Close()
+	delete(m.tables, t.ID)
+}
+
+// sit claims the game at a table for the players at it. Once players have sat
+// at a table only their token lets them back in, and only while their seat is
+// empty.
+func (m *GameManager) sit(id, token string) (*table, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[id]
+	switch {
+	case !ok:
+		return nil, ErrTableNotFound
+	case t.Playing, t.token != "" && token != t.token:
+		return nil, ErrTableTaken
+	}
+
+	if t.token == "" {
+		t.token = newSeatToken()
+	}
+	if t.closing != nil {
+		t.closing.Stop()
+		t.closing = nil
+	}
+
+	t.Playing = true
+	return t, nil
+}
+
+// stand empties the seat at a table when its players' connection drops,
+// closing the table unless they're back within the reconnect grace.
+func (m *GameManager) stand(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[id]
+	if !ok {
+		return
+	}
+
+	t.Playing = false
+	m.closeUnlessSeated(t, m.reconnectGrace)
+}
+
+// closeUnlessSeated closes t after d unless players sit at it first. It's
+// called with m.mu held.
+func (m *GameManager) closeUnlessSeated(t *table, d time.Duration) {
+	var closing clock.Timer
+	closing = m.clock.AfterFunc(d, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if m.tables[t.ID] == t && t.closing == closing {
+			m.close(t)
+		}
+	})
+	t.closing = closing
+}
+
+// watch adds a spectator to a table until the returned func is called.
+func (m *GameManager) watch(id string) (*table, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[id]
+	if !ok {
+		return nil, nil, ErrTableNotFound
+	}
+
+	t.Spectators++
+	leave := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		t.Spectators--
+	}
+
+	return t, leave, nil
+}
+
+// started notes who is playing at a table.
+func (m *GameManager) started(id string, setup GameSetup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tables[id]; ok {
+		t.NumberOfPlayers = setup.NumberOfPlayers
+		t.Participants = setup.Participants
+	}
+}
+
+func newSeatToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
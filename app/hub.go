@@ -0,0 +1,84 @@
+package poker
+
+import "sync"
+
+// Hub fans messages out to its subscribers, each with a buffer of its own. A
+// subscriber that falls a full buffer behind is dropped rather than holding up
+// the others.
+type Hub[T any] struct {
+	buffer int
+
+	mu          sync.Mutex
+	subscribers map[<-chan T]chan T
+	closed      bool
+}
+
+func NewHub[T any](buffer int) *Hub[T] {
+	return &Hub[T]{
+		buffer:      buffer,
+		subscribers: map[<-chan T]chan T{},
+	}
+}
+
+// Subscribe returns a channel of the messages published from now on. It is
+// closed when the hub closes, the subscriber is dropped for being slow, or
+// it unsubscribes.
+func (h *Hub[T]) Subscribe() <-chan T {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan T, h.buffer)
+	if h.closed {
+		close(c)
+		return c
+	}
+
+	h.subscribers[c] = c
+	return c
+}
+
+func (h *Hub[T]) Unsubscribe(c <-chan T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(c)
+}
+
+// Publish sends msg to every subscriber without waiting on any of them.
+func (h *Hub[T]) Publish(msg T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c, send := range h.subscribers {
+		select {
+		case send <- msg:
+		default:
+			h.drop(c)
+		}
+	}
+}
+
+// Close lets every subscriber go once they've had what was published.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.subscribers {
+		h.drop(c)
+	}
+	h.closed = true
+}
+
+func (h *Hub[T]) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers)
+}
+
+func (h *Hub[T]) drop(c <-chan T) {
+	if send, ok := h.subscribers[c]; ok {
+		close(send)
+		delete(h.subscribers, c)
+	}
+}
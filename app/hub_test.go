@@ -0,0 +1,65 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("every subscriber gets what is published", func(t *testing.T) {
+		hub := poker.NewHub[int](4)
+		first, second := hub.Subscribe(), hub.Subscribe()
+
+		hub.Publish(1)
+		hub.Publish(2)
+		hub.Close()
+
+		assertReceived(t, first, 1, 2)
+		assertReceived(t, second, 1, 2)
+	})
+
+	t.Run("a slow subscriber is dropped without holding up the others", func(t *testing.T) {
+		hub := poker.NewHub[int](2)
+		slow, fast := hub.Subscribe(), hub.Subscribe()
+
+		for i := 1; i <= 4; i++ {
+			hub.Publish(i)
+			<-fast
+		}
+
+		assertReceived(t, slow, 1, 2)
+		if hub.Subscribers() != 1 {
+			t.Errorf("got %d subscribers want 1", hub.Subscribers())
+		}
+	})
+
+	t.Run("unsubscribing and subscribing after closing", func(t *testing.T) {
+		hub := poker.NewHub[int](2)
+		gone := hub.Subscribe()
+
+		hub.Unsubscribe(gone)
+		hub.Publish(1)
+		assertReceived(t, gone)
+
+		hub.Close()
+		assertReceived(t, hub.Subscribe())
+	})
+}
+
+func assertReceived(t testing.TB, c <-chan int, want ...int) {
+	t.Helper()
+
+	got := []int{}
+	for msg := range c {
+		got = append(got, msg)
+	}
+
+	if want == nil {
+		want = []int{}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 )
 
 type League []Player
@@ -18,6 +19,69 @@ func (league League) Find(name string) *Player {
 	return nil
 }
 
+func (league League) withWin(name string) League {
+	player := league.Find(name)
+
+	if player != nil {
+		player.Wins++
+		return league
+	}
+
+	return append(league, Player{name, 1})
+}
+
+func (league League) withScore(name string, wins int) League {
+	changed := append(League{}, league...)
+
+	if player := changed.Find(name); player != nil {
+		player.Wins = wins
+		return changed
+	}
+
+	return append(changed, Player{name, wins})
+}
+
+func (league League) without(name string) League {
+	changed := League{}
+
+	for _, player := range league {
+		if player.Name != name {
+			changed = append(changed, player)
+		}
+	}
+
+	return changed
+}
+
+// merged moves the wins of from onto into, renaming from when into isn't in
+// the league yet.
+func (league League) merged(from, into string) League {
+	source := league.Find(from)
+	if source == nil {
+		return append(League{}, league...)
+	}
+
+	wins := source.Wins
+	changed := league.without(from)
+
+	if target := changed.Find(into); target != nil {
+		target.Wins += wins
+		return changed
+	}
+
+	return append(changed, Player{into, wins})
+}
+
+func (league League) sortedByWins() League {
+	sorted := append(League{}, league...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Wins > sorted[j].Wins
+	})
+
+	return sorted
+}
+
 func NewLeague(reader io.Reader) (League, error) {
 	var league League
 	err := json.NewDecoder(reader).Decode(&league)
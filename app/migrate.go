@@ -0,0 +1,246 @@
+package poker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LeagueImporter is implemented by stores that can set the wins of a whole
+// league in one write, instead of one SetPlayerScore call per player.
+type LeagueImporter interface {
+	ImportLeague(league League) error
+}
+
+type MigrationReport struct {
+	Players int
+	Wins    int
+	Games   int
+	Seasons int
+}
+
+// MigrateLeague copies every player's wins, every game and every closed season
+// from one store into another, which must be empty. With dryRun set nothing is
+// written to the target, which may be nil so it's never opened.
+func MigrateLeague(from, to PlayerStore, dryRun bool) (MigrationReport, error) {
+	var report MigrationReport
+
+	if to != nil {
+		if existing := to.GetLeague(); len(existing) > 0 {
+			return report, fmt.Errorf("target store already has %d players, refusing to migrate into it", len(existing))
+		}
+
+		if existing := to.GetGames(); len(existing) > 0 {
+			return report, fmt.Errorf("target store already has %d games, refusing to migrate into it", len(existing))
+		}
+	}
+
+	league := from.GetLeague()
+	for _, player := range league {
+		report.Players++
+		report.Wins += player.Wins
+	}
+
+	games := from.GetGames()
+	report.Games = len(games)
+
+	seasons := from.GetSeasons()
+	report.Seasons = len(seasons)
+
+	if dryRun {
+		return report, nil
+	}
+
+	for i := len(games) - 1; i >= 0; i-- {
+		if err := to.RecordGame(games[i]); err != nil {
+			return report, err
+		}
+	}
+
+	if err := migrateWins(league, to); err != nil {
+		return report, err
+	}
+
+	for _, season := range seasons {
+		if err := to.ArchiveSeason(season); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// storeSidecars are the files a store may keep next to its own, which have to
+// go wherever it goes.
+var storeSidecars = []string{"-wal", "-shm", journalSuffix}
+
+// MigrateLeagueToFile migrates from into the kind of store at path. The store
+// is written next to path and only moved over it once it has been verified,
+// so a migration that fails leaves path as it was. Any store already at path
+// must be empty, which a dry run checks too, on a copy so path isn't touched.
+func MigrateLeagueToFile(from PlayerStore, kind, path string, dryRun bool) (MigrationReport, error) {
+	path = StorePath(kind, path)
+
+	report, err := migrateIntoCopy(from, kind, path)
+	if err != nil || dryRun {
+		return report, err
+	}
+
+	building := path + ".migrating"
+	removeStore(building)
+
+	if err := migrateInto(from, kind, building); err != nil {
+		removeStore(building)
+		return report, err
+	}
+
+	// the store at path is empty, so anything left next to it is stale
+	for _, suffix := range storeSidecars {
+		os.Remove(path + suffix)
+	}
+
+	if err := os.Rename(building, path); err != nil {
+		removeStore(building)
+		return report, fmt.Errorf("problem moving migrated store to %s, %v", path, err)
+	}
+
+	return report, syncDir(filepath.Dir(path))
+}
+
+// migrateIntoCopy dry runs the migration into a copy of the store at path, if
+// there is one, to check it's empty.
+func migrateIntoCopy(from PlayerStore, kind, path string) (MigrationReport, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return MigrateLeague(from, nil, true)
+	}
+
+	dir, err := os.MkdirTemp("", "migrate")
+	if err != nil {
+		return MigrationReport{}, fmt.Errorf("problem copying target store, %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	copied := filepath.Join(dir, filepath.Base(path))
+	for _, suffix := range append([]string{""}, storeSidecars...) {
+		data, err := os.ReadFile(path + suffix)
+		if os.IsNotExist(err) && suffix != "" {
+			continue
+		}
+		if err != nil {
+			return MigrationReport{}, fmt.Errorf("problem copying target store, %v", err)
+		}
+		if err := os.WriteFile(copied+suffix, data, 0600); err != nil {
+			return MigrationReport{}, fmt.Errorf("problem copying target store, %v", err)
+		}
+	}
+
+	to, closeTo, err := OpenPlayerStore(kind, copied)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	defer closeTo()
+
+	return MigrateLeague(from, to, true)
+}
+
+// migrateInto migrates from into a new store at path and verifies it, closing
+// the store before it returns.
+func migrateInto(from PlayerStore, kind, path string) error {
+	to, closeTo, err := OpenPlayerStore(kind, path)
+	if err != nil {
+		return err
+	}
+	defer closeTo()
+
+	if _, err := MigrateLeague(from, to, false); err != nil {
+		return err
+	}
+
+	return VerifyMigration(from, to)
+}
+
+// removeStore removes the store at path and anything it left next to it.
+func removeStore(path string) {
+	os.Remove(path)
+	for _, suffix := range storeSidecars {
+		os.Remove(path + suffix)
+	}
+}
+
+// migrateWins sets every player's wins to what they are in league. Recording
+// the games already counted their wins, but the league also has wins that were
+// recorded without a game or edited since.
+func migrateWins(league League, to PlayerStore) error {
+	wins := append(League{}, league...)
+	for _, player := range to.GetLeague() {
+		if wins.Find(player.Name) == nil {
+			wins = append(wins, Player{player.Name, 0})
+		}
+	}
+
+	if importer, ok := to.(LeagueImporter); ok {
+		return importer.ImportLeague(wins)
+	}
+
+	for _, player := range wins {
+		if err := to.SetPlayerScore(player.Name, player.Wins); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VerifyMigration checks both stores return the same league and games.
+// Players with the same number of wins may be listed in a different order by
+// each store.
+func VerifyMigration(from, to PlayerStore) error {
+	want := from.GetLeague()
+	got := to.GetLeague()
+
+	wins := make(map[string]int, len(got))
+	for _, player := range got {
+		wins[player.Name] = player.Wins
+	}
+
+	var problems []string
+
+	if len(got) != len(want) {
+		problems = append(problems, fmt.Sprintf("target has %d players, source has %d", len(got), len(want)))
+	}
+
+	for _, player := range want {
+		if gotWins, ok := wins[player.Name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is missing from target", player.Name))
+		} else if gotWins != player.Wins {
+			problems = append(problems, fmt.Sprintf("%s has %d wins in target, %d in source", player.Name, gotWins, player.Wins))
+		}
+	}
+
+	gotGames := make(map[string]bool)
+	for _, game := range to.GetGames() {
+		gotGames[game.ID] = true
+	}
+
+	wantGames := from.GetGames()
+	if len(gotGames) != len(wantGames) {
+		problems = append(problems, fmt.Sprintf("target has %d games, source has %d", len(gotGames), len(wantGames)))
+	}
+
+	for _, game := range wantGames {
+		if !gotGames[game.ID] {
+			problems = append(problems, fmt.Sprintf("game %s is missing from target", game.ID))
+		}
+	}
+
+	if got, want := len(to.GetSeasons()), len(from.GetSeasons()); got != want {
+		problems = append(problems, fmt.Sprintf("target has %d seasons, source has %d", got, want))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("league differs after migration, %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
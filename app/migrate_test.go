@@ -0,0 +1,187 @@
+package poker_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestMigrateLeague(t *testing.T) {
+	newSource := func(t *testing.T) poker.PlayerStore {
+		database, cleanDatabaseFn := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10},
+			{"Name": "Chris", "Wins": 33}]`)
+		t.Cleanup(cleanDatabaseFn)
+
+		store, err := poker.NewFileSystemStore(database)
+		poker.AssertNoError(t, err)
+		return store
+	}
+
+	for _, kind := range []string{poker.JSONStoreKind, poker.EventLogStoreKind, poker.SQLiteStoreKind} {
+		t.Run("json to "+kind, func(t *testing.T) {
+			from := newSource(t)
+			to, closeFn, err := poker.OpenPlayerStore(kind, filepath.Join(t.TempDir(), "target"))
+			poker.AssertNoError(t, err)
+			defer closeFn()
+
+			report, err := poker.MigrateLeague(from, to, false)
+			poker.AssertNoError(t, err)
+
+			assertMigrationReport(t, report, poker.MigrationReport{Players: 2, Wins: 43})
+			poker.AssertNoError(t, poker.VerifyMigration(from, to))
+		})
+	}
+
+	t.Run("games count their wins only once", func(t *testing.T) {
+		database, cleanDatabaseFn := createTempFile(t, `{
+			"League": [{"Name": "Chris", "Wins": 3}, {"Name": "Cleo", "Wins": 1}],
+			"Games": [{"ID": "a", "Winner": "Chris"}, {"ID": "b", "Winner": "Cleo"}]}`)
+		defer cleanDatabaseFn()
+		from, err := poker.NewFileSystemStore(database)
+		poker.AssertNoError(t, err)
+
+		to, closeFn, err := poker.OpenPlayerStore(poker.SQLiteStoreKind, filepath.Join(t.TempDir(), "target"))
+		poker.AssertNoError(t, err)
+		defer closeFn()
+
+		report, err := poker.MigrateLeague(from, to, false)
+		poker.AssertNoError(t, err)
+
+		assertMigrationReport(t, report, poker.MigrationReport{Players: 2, Wins: 4, Games: 2})
+		poker.AssertNoError(t, poker.VerifyMigration(from, to))
+	})
+
+	t.Run("dry run does not write to the target", func(t *testing.T) {
+		from := newSource(t)
+		to := &poker.StubPlayerStore{}
+
+		report, err := poker.MigrateLeague(from, to, true)
+		poker.AssertNoError(t, err)
+
+		assertMigrationReport(t, report, poker.MigrationReport{Players: 2, Wins: 43})
+		if len(to.WinCalls) != 0 {
+			t.Errorf("expected no wins recorded but got %v", to.WinCalls)
+		}
+	})
+
+	t.Run("dry run needs no target", func(t *testing.T) {
+		report, err := poker.MigrateLeague(newSource(t), nil, true)
+		poker.AssertNoError(t, err)
+
+		assertMigrationReport(t, report, poker.MigrationReport{Players: 2, Wins: 43})
+	})
+
+	t.Run("refuses to migrate into a store that has players", func(t *testing.T) {
+		to := &poker.StubPlayerStore{League: poker.League{{"Pepper", 1}}}
+
+		_, err := poker.MigrateLeague(newSource(t), to, false)
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestMigrateLeagueToFile(t *testing.T) {
+	from := &poker.StubPlayerStore{League: poker.League{{"Chris", 33}, {"Cleo", 10}}}
+
+	t.Run("migrates into a new store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "target")
+
+		report, err := poker.MigrateLeagueToFile(from, poker.SQLiteStoreKind, path, false)
+		poker.AssertNoError(t, err)
+		assertMigrationReport(t, report, poker.MigrationReport{Players: 2, Wins: 43})
+
+		files, err := os.ReadDir(filepath.Dir(path))
+		poker.AssertNoError(t, err)
+		if len(files) != 1 || files[0].Name() != "target" {
+			t.Errorf("got files %v want just the target", files)
+		}
+
+		to, closeFn, err := poker.OpenPlayerStore(poker.SQLiteStoreKind, path)
+		poker.AssertNoError(t, err)
+		defer closeFn()
+		poker.AssertNoError(t, poker.VerifyMigration(from, to))
+	})
+
+	t.Run("dry run neither creates the target nor writes to it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "target")
+
+		_, err := poker.MigrateLeagueToFile(from, poker.SQLiteStoreKind, path, true)
+		poker.AssertNoError(t, err)
+		assertNoFiles(t, filepath.Dir(path))
+	})
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("refuses a target that has players with dry run %v", dryRun), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "target.json")
+			existing := []byte(`[{"Name": "Pepper", "Wins": 1}]`)
+			poker.AssertNoError(t, os.WriteFile(path, existing, 0600))
+
+			if _, err := poker.MigrateLeagueToFile(from, poker.JSONStoreKind, path, dryRun); err == nil {
+				t.Error("expected an error but didn't get one")
+			}
+
+			got, err := os.ReadFile(path)
+			poker.AssertNoError(t, err)
+			if !bytes.Equal(got, existing) {
+				t.Errorf("got target %s want it left as %s", got, existing)
+			}
+		})
+	}
+
+	t.Run("a migration that fails leaves nothing behind", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "target")
+		from := &poker.StubPlayerStore{Games: []poker.GameRecord{{ID: "a", Winner: "Chris"}, {ID: "a", Winner: "Cleo"}}}
+
+		if _, err := poker.MigrateLeagueToFile(from, poker.SQLiteStoreKind, path, false); err == nil {
+			t.Fatal("expected an error migrating two games with the same id")
+		}
+		assertNoFiles(t, filepath.Dir(path))
+	})
+}
+
+func assertNoFiles(t testing.TB, dir string) {
+	t.Helper()
+
+	files, err := os.ReadDir(dir)
+	poker.AssertNoError(t, err)
+	if len(files) != 0 {
+		t.Errorf("got files %v want none", files)
+	}
+}
+
+func TestVerifyMigration(t *testing.T) {
+	from := &poker.StubPlayerStore{League: poker.League{{"Chris", 33}, {"Cleo", 10}, {"Pepper", 10}}}
+
+	t.Run("ties can be in any order", func(t *testing.T) {
+		to := &poker.StubPlayerStore{League: poker.League{{"Chris", 33}, {"Pepper", 10}, {"Cleo", 10}}}
+		poker.AssertNoError(t, poker.VerifyMigration(from, to))
+	})
+
+	t.Run("reports differing wins", func(t *testing.T) {
+		to := &poker.StubPlayerStore{League: poker.League{{"Chris", 32}, {"Cleo", 10}, {"Pepper", 10}}}
+		if poker.VerifyMigration(from, to) == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("reports missing players", func(t *testing.T) {
+		to := &poker.StubPlayerStore{League: poker.League{{"Chris", 33}, {"Cleo", 10}}}
+		if poker.VerifyMigration(from, to) == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func assertMigrationReport(t testing.TB, got, want poker.MigrationReport) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("got report %+v want %+v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package poker
+
+import "sync"
+
+// LeagueWatcher is implemented by stores that can tell when the league
+// changes. Changes made while a watcher is busy are merged into one, so a
+// slow watcher only ever hears that the league changed since it last looked.
+type LeagueWatcher interface {
+	WatchLeague() <-chan struct{}
+	UnwatchLeague(<-chan struct{})
+}
+
+// NotifyingStore is a PlayerStore that tells its watchers whenever a write
+// changes the league. Everything that writes to the store has to go through
+// it for the watchers to hear about it.
+type NotifyingStore struct {
+	PlayerStore
+
+	mu       sync.Mutex
+	watchers map[<-chan struct{}]chan struct{}
+}
+
+func NewNotifyingStore(store PlayerStore) *NotifyingStore {
+	return &NotifyingStore{
+		PlayerStore: store,
+		watchers:    map[<-chan struct{}]chan struct{}{},
+	}
+}
+
+func (s *NotifyingStore) WatchLeague() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := make(chan struct{}, 1)
+	s.watchers[c] = c
+	return c
+}
+
+// UnwatchLeague closes c once any change still pending on it is read.
+func (s *NotifyingStore) UnwatchLeague(c <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if send, ok := s.watchers[c]; ok {
+		close(send)
+		delete(s.watchers, c)
+	}
+}
+
+func (s *NotifyingStore) RecordWin(name string) error {
+	return s.notify(s.PlayerStore.RecordWin(name))
+}
+
+func (s *NotifyingStore) RecordGame(game GameRecord) error {
+	return s.notify(s.PlayerStore.RecordGame(game))
+}
+
+func (s *NotifyingStore) ArchiveSeason(season Season) error {
+	return s.notify(s.PlayerStore.ArchiveSeason(season))
+}
+
+func (s *NotifyingStore) SetPlayerScore(name string, wins int) error {
+	return s.notify(s.PlayerStore.SetPlayerScore(name, wins))
+}
+
+func (s *NotifyingStore) DeletePlayer(name string) error {
+	return s.notify(s.PlayerStore.DeletePlayer(name))
+}
+
+func (s *NotifyingStore) RenamePlayer(from, to string) error {
+	return s.notify(s.PlayerStore.RenamePlayer(from, to))
+}
+
+func (s *NotifyingStore) MergePlayers(from, into string) error {
+	return s.notify(s.PlayerStore.MergePlayers(from, into))
+}
+
+// notify tells the watchers about a write unless it failed. Watchers with a
+// change already pending will see this one when they read it.
+func (s *NotifyingStore) notify(err error) error {
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, send := range s.watchers {
+		select {
+		case send <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package poker
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pageLayout defines the parts the pages share.
+const pageLayout = "layout.html"
+
+// pages are the templates the server renders, each parsed with pageLayout.
+var pages = []string{htmlTemplatePath, "league.html", "player.html", "games.html", "login.html"}
+
+var pageFuncs = template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+	"date":    func(t time.Time) string { return t.Format("2 Jan 2006 15:04") },
+
+	// pathEscape lets names with a / or ? in them be part of a link's path.
+	"pathEscape": url.PathEscape,
+
+	// sortBy links to the same page sorted by another column.
+	"sortBy": func(query url.Values, by string) template.URL {
+		sorted := url.Values{}
+		for key, values := range query {
+			sorted[key] = values
+		}
+		sorted.Set("sort", by)
+		return template.URL("?" + sorted.Encode())
+	},
+}
+
+// parsePages parses every page in assets, by name.
+func parsePages(assets fs.FS) (map[string]*template.Template, error) {
+	parsed := make(map[string]*template.Template, len(pages))
+
+	for _, name := range pages {
+		tmpl, err := template.New(name).Funcs(pageFuncs).ParseFS(assets, name, pageLayout)
+		if err != nil {
+			return nil, fmt.Errorf("problem opening %s %v", name, err)
+		}
+		parsed[name] = tmpl
+	}
+
+	return parsed, nil
+}
+
+// renderPage writes the page called name with status, parsed again first
+// when the assets come from a directory.
+func (s *PlayerServer) renderPage(w http.ResponseWriter, status int, name string, data interface{}) {
+	tmpl := s.pages[name]
+	if s.assetsDir != "" {
+		parsed, err := parsePages(s.assets)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tmpl = parsed[name]
+	}
+
+	var page bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&page, name, data); err != nil {
+		http.Error(w, fmt.Sprintf("problem rendering %s %v", name, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	page.WriteTo(w)
+}
+
+// prefersHTML reports whether the request's Accept header ranks HTML above
+// JSON, so browsers get pages while everything else keeps getting JSON.
+func prefersHTML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return acceptQuality(accept, "text/html") > acceptQuality(accept, "application/json")
+}
+
+// acceptQuality is the q value an Accept header gives mediaType, from its
+// most specific matching range.
+func acceptQuality(accept, mediaType string) float64 {
+	kind, _, _ := strings.Cut(mediaType, "/")
+
+	quality, specificity := 0.0, 0
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		matches := 0
+		switch accepted {
+		case mediaType:
+			matches = 3
+		case kind + "/*":
+			matches = 2
+		case "*/*":
+			matches = 1
+		}
+		if matches <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		quality, specificity = q, matches
+	}
+
+	return quality
+}
@@ -0,0 +1,116 @@
+package poker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+func TestPages(t *testing.T) {
+	finished := time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)
+	store := &poker.StubPlayerStore{
+		League: poker.League{{Name: "Pepper", Wins: 3}, {Name: "Chris", Wins: 1}},
+		Games: []poker.GameRecord{
+			{ID: "2", FinishedAt: finished, Participants: []string{"Pepper", "Chris"}, Winner: "Chris"},
+			{ID: "1", FinishedAt: finished.Add(-time.Hour), Participants: []string{"Ruth", "Cleo"}, Winner: "Ruth"},
+		},
+	}
+	server := mustCreatePlayerServer(t, store, dummyGame)
+
+	t.Run("browsers get the league as a page", func(t *testing.T) {
+		response := getPage(server, "/league", browserAccept)
+
+		assertPage(t, response, `<a href="/players/Pepper">Pepper</a>`, `<a href="/players/Chris">Chris</a>`, "17 Oct 2026 22:00")
+	})
+
+	t.Run("sorting the league keeps to the season", func(t *testing.T) {
+		response := getPage(server, "/league?season=2026-10", browserAccept)
+
+		assertPage(t, response, `<a href="?season=2026-10&amp;sort=wins">Wins</a>`, `<a href="?season=2026-10&amp;sort=rating">Rating</a>`)
+	})
+
+	t.Run("players are linked to whatever their name", func(t *testing.T) {
+		name := "Ann/Bo? #1"
+		store := &poker.StubPlayerStore{
+			League: poker.League{{Name: name, Wins: 1}},
+			Games:  []poker.GameRecord{{ID: "1", FinishedAt: finished, Participants: []string{name, "Chris"}, Winner: name}},
+		}
+		server := mustCreatePlayerServer(t, store, dummyGame)
+
+		link := "/players/Ann%2FBo%3F%20%231"
+		for _, path := range []string{"/league", "/games"} {
+			assertPage(t, getPage(server, path, browserAccept), `<a href="`+link+`">`)
+		}
+
+		assertPage(t, getPage(server, link, browserAccept), "<h1>Ann/Bo? #1</h1>")
+	})
+
+	t.Run("a player's page lists the games they played", func(t *testing.T) {
+		response := getPage(server, "/players/Chris", browserAccept)
+
+		assertPage(t, response, "<h1>Chris</h1>", "100%", "17 Oct 2026 22:00")
+		if strings.Contains(response.Body.String(), "Cleo") {
+			t.Error("expected only Chris's games on their page")
+		}
+	})
+
+	t.Run("recent games have a page", func(t *testing.T) {
+		response := getPage(server, "/games", browserAccept)
+
+		assertPage(t, response, `<a href="/players/Ruth">Ruth</a>`, `<a href="/players/Cleo">Cleo</a>`)
+	})
+
+	t.Run("everything else keeps getting JSON", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json", "application/json, text/html;q=0.5"} {
+			for _, path := range []string{"/league", "/players/Chris", "/games"} {
+				response := getPage(server, path, accept)
+
+				poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+				if got := response.Header().Get("content-type"); got != "application/json" {
+					t.Errorf("got content type %q for %s accepting %q, want application/json", got, path, accept)
+				}
+			}
+		}
+	})
+
+	t.Run("an unknown player has no page", func(t *testing.T) {
+		response := getPage(server, "/players/Nobody", browserAccept)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func getPage(server http.Handler, path, accept string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	return response
+}
+
+func assertPage(t testing.TB, response *httptest.ResponseRecorder, want ...string) {
+	t.Helper()
+
+	poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+	if got := response.Header().Get("content-type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("got content type %q want text/html", got)
+	}
+	if got := response.Header().Get("Vary"); got != "Accept" {
+		t.Errorf("got Vary %q want Accept", got)
+	}
+
+	for _, text := range want {
+		if !strings.Contains(response.Body.String(), text) {
+			t.Errorf("expected %q in the page, got %s", text, response.Body.String())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type playerServerSSE struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newPlayerServerSSE starts an event stream in response to the request, which
+// has already been answered when it fails.
+func newPlayerServerSSE(w http.ResponseWriter) (*playerServerSSE, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		err := errors.New("streaming unsupported")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &playerServerSSE{w: w, flusher: flusher}, nil
+}
+
+// relay streams everything that happens at a table until the table lets the
+// client go or the client leaves, with a comment every heartbeat so idle
+// connections aren't dropped along the way.
+func (s *playerServerSSE) relay(ctx context.Context, happened []WSMessage, messages <-chan WSMessage, heartbeat time.Duration) {
+	for _, msg := range happened {
+		if err := s.send(msg); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": ping\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if err := s.send(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// send writes msg as an event named after its type, with the same JSON the
+// websocket carries as its data. Only numbered events have an id to resume
+// from.
+func (s *playerServerSSE) send(msg WSMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("problem encoding event %v", err)
+	}
+
+	event := fmt.Sprintf("event: %s\ndata: %s\n\n", msg.Type, data)
+	if msg.EventID != 0 {
+		event = fmt.Sprintf("id: %d\n", msg.EventID) + event
+	}
+
+	return s.write(event)
+}
+
+func (s *playerServerSSE) write(event string) error {
+	if _, err := fmt.Fprint(s.w, event); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+	return nil
+}
@@ -1,26 +1,248 @@
 package poker
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// WSProtocolVersion is the version of the game's websocket messages, which
+// every message carries.
+const WSProtocolVersion = 1
+
+// wsWriteWait is how long a client has to take a message before it's given up
+// on.
+const wsWriteWait = 10 * time.Second
+
+// DefaultPongWait is how long a websocket client can go without answering a
+// ping before it's taken to be gone. Clients are pinged a little more often.
+const DefaultPongWait = 60 * time.Second
+
+// Types of websocket message. Players send start_game, control and
+// declare_winner, and the server sends the rest.
+const (
+	StartGameMessage      = "start_game"
+	ControlMessage        = "control"
+	DeclareWinnerMessage  = "declare_winner"
+	SeatedMessage         = "seated"
+	GameStartedMessage    = "game_started"
+	BlindUpdateMessage    = "blind_update"
+	ControlAppliedMessage = "control_applied"
+	ErrorMessage          = "error"
+	GameOverMessage       = "game_over"
+	LeagueUpdateMessage   = "league_update"
+	ServerClosingMessage  = "server_closing"
+)
+
+// WSMessage is a message on the game websocket. Which fields are set depends
+// on its type:
+//
+//	start_game       Players or Participants, and optionally a Blinds preset
+//	control          Command, one of pause, resume, next or rewind
+//	declare_winner   Winner
+//	seated           GameID and the Token to reconnect to it with
+//	game_started     GameID, Players and Participants
+//	blind_update     Blind
+//	control_applied  Command
+//	error            Error
+//	game_over        GameID and Winner
+//	league_update    Standings, on the league's own websocket
+//	server_closing   nothing, the server is shutting down and the game is over
+type WSMessage struct {
+	Version int    `json:"version"`
+	Type    string `json:"type"`
+
+	Players      int          `json:"players,omitempty"`
+	Participants []string     `json:"participants,omitempty"`
+	Blinds       string       `json:"blinds,omitempty"`
+	Command      string       `json:"command,omitempty"`
+	Winner       string       `json:"winner,omitempty"`
+	GameID       string       `json:"game_id,omitempty"`
+	Blind        *BlindUpdate `json:"blind,omitempty"`
+	Error        string       `json:"error,omitempty"`
+	Token        string       `json:"token,omitempty"`
+
+	Standings []PlayerStanding `json:"standings,omitempty"`
+
+	// EventID numbers the events at a table, for clients of its event
+	// stream to resume from.
+	EventID int `json:"-"`
+}
+
+// gameSetup validates a start_game message.
+func (m WSMessage) gameSetup(id string) (GameSetup, error) {
+	setup := GameSetup{ID: id, NumberOfPlayers: m.Players}
+
+	if len(m.Participants) > 0 {
+		for _, name := range m.Participants {
+			if name = strings.TrimSpace(name); name == "" {
+				return GameSetup{}, errors.New("participants can't be blank")
+			}
+			setup.Participants = append(setup.Participants, name)
+		}
+
+		if m.Players != 0 && m.Players != len(setup.Participants) {
+			return GameSetup{}, fmt.Errorf("%d players but %d participants", m.Players, len(setup.Participants))
+		}
+		setup.NumberOfPlayers = len(setup.Participants)
+	}
+
+	if setup.NumberOfPlayers < 2 {
+		return GameSetup{}, errors.New(BadPlayerInputErrMsg)
+	}
+
+	if m.Blinds != "" {
+		blinds, err := BlindPreset(m.Blinds)
+		if err != nil {
+			return GameSetup{}, err
+		}
+		setup.Blinds = &blinds
+	}
+
+	return setup, nil
+}
+
 type playerServerWS struct {
 	*websocket.Conn
+
+	// Alerts and replies are written from different goroutines, but a
+	// connection only supports one writer at a time.
+	writeMu sync.Mutex
+
+	pongWait  time.Duration
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
-func (w *playerServerWS) WaitForMsg() string {
-	_, msg, err := w.ReadMessage()
-	if err != nil {
-		log.Printf("error reading from websocket %v\n", err)
+// keepAlive pings the client until the connection closes, giving up on
+// reading from it when it stops answering.
+func (w *playerServerWS) keepAlive() {
+	w.SetReadDeadline(time.Now().Add(w.pongWait))
+	w.SetPongHandler(func(string) error {
+		return w.SetReadDeadline(time.Now().Add(w.pongWait))
+	})
+
+	go func() {
+		ticker := time.NewTicker(w.pongWait * 9 / 10)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				if err := w.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (w *playerServerWS) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.Conn.Close()
+	})
+	return err
+}
+
+// next reads the next message, replying with an error to any that is
+// malformed or of another version. It returns false once the connection is
+// gone.
+func (w *playerServerWS) next() (WSMessage, bool) {
+	for {
+		_, data, err := w.ReadMessage()
+		if err != nil {
+			log.Printf("error reading from websocket %v\n", err)
+			return WSMessage{}, false
+		}
+		w.SetReadDeadline(time.Now().Add(w.pongWait))
+
+		var msg WSMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			w.sendError(fmt.Errorf("invalid message, %v", err))
+			continue
+		}
+
+		if msg.Version != WSProtocolVersion {
+			w.sendError(fmt.Errorf("unsupported protocol version %d, expected %d", msg.Version, WSProtocolVersion))
+			continue
+		}
+
+		return msg, true
 	}
+}
 
-	return string(msg)
+// closedByPeer reads from a client that has nothing to say, answering
+// anything it sends with refusal, and is closed once the client has gone.
+func (w *playerServerWS) closedByPeer(refusal error) <-chan struct{} {
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		for {
+			if _, ok := w.next(); !ok {
+				return
+			}
+			w.sendError(refusal)
+		}
+	}()
+
+	return closed
 }
 
+// relay sends everything that happens at a table to the client, closing the
+// connection once the table lets it go or it can't keep up.
+func (w *playerServerWS) relay(happened []WSMessage, messages <-chan WSMessage) {
+	defer w.Close()
+
+	for _, msg := range happened {
+		if err := w.send(msg); err != nil {
+			return
+		}
+	}
+
+	for msg := range messages {
+		if err := w.send(msg); err != nil {
+			return
+		}
+	}
+}
+
+func (w *playerServerWS) send(msg WSMessage) error {
+	msg.Version = WSProtocolVersion
+
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return w.WriteJSON(msg)
+}
+
+func (w *playerServerWS) sendError(err error) error {
+	return w.send(WSMessage{Type: ErrorMessage, Error: err.Error()})
+}
+
+func (w *playerServerWS) WriteBlindUpdate(update BlindUpdate) error {
+	return w.send(WSMessage{Type: BlindUpdateMessage, Blind: &update})
+}
+
+// Write sends p as a text frame outside of the protocol. Games announce
+// blinds with WriteBlindUpdate instead.
 func (w *playerServerWS) Write(p []byte) (n int, err error) {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	w.SetWriteDeadline(time.Now().Add(wsWriteWait))
 	err = w.WriteMessage(websocket.TextMessage, p)
 	if err != nil {
 		return 0, err
@@ -29,11 +251,17 @@ func (w *playerServerWS) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func newPlayerServerWS(w http.ResponseWriter, r *http.Request) *playerServerWS {
-	conn, err := wsUpgrader.Upgrade(w, r, nil)
+// newPlayerServerWS upgrades the request and keeps the connection alive with
+// pings. The request has already been answered when it fails.
+func newPlayerServerWS(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader, pongWait time.Duration) (*playerServerWS, error) {
+	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("problem upgrading connection to WebSockets %v\n", err)
+		return nil, err
 	}
 
-	return &playerServerWS{conn}
+	ws := &playerServerWS{Conn: conn, pongWait: pongWait, done: make(chan struct{})}
+	ws.keepAlive()
+
+	return ws, nil
 }
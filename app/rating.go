@@ -0,0 +1,141 @@
+package poker
+
+import (
+	"math"
+	"sync"
+)
+
+type Ratings map[string]float64
+
+// RatingEngine rates players from the games they played. Ratings are not
+// stored, they are worked out by replaying every recorded game in the order
+// they finished, so switching engine re-rates the whole history.
+type RatingEngine interface {
+	Initial() float64
+	Rate(ratings Ratings, game GameRecord)
+}
+
+// Elo rates a multi-player game as the winner beating every other player.
+// K is shared between those match ups so a big table doesn't swing ratings
+// more than a heads up game.
+type Elo struct {
+	K              float64
+	StartingRating float64
+}
+
+var DefaultRatingEngine RatingEngine = Elo{K: 32, StartingRating: 1500}
+
+func (e Elo) Initial() float64 {
+	return e.StartingRating
+}
+
+func (e Elo) Rate(ratings Ratings, game GameRecord) {
+	if !ratable(game) {
+		return
+	}
+	players := playersIn(game)
+
+	for _, name := range players {
+		if _, ok := ratings[name]; !ok {
+			ratings[name] = e.StartingRating
+		}
+	}
+
+	k := e.K / float64(len(players)-1)
+	winner := ratings[game.Winner]
+
+	for _, name := range players {
+		if name == game.Winner {
+			continue
+		}
+
+		expected := 1 / (1 + math.Pow(10, (ratings[name]-winner)/400))
+		change := k * (1 - expected)
+
+		ratings[game.Winner] += change
+		ratings[name] -= change
+	}
+}
+
+// ratable is whether game says how its players compare, which needs a winner
+// who beat at least one other participant. The winner of a game is cleared
+// when they're deleted, and wins posted on their own have no participants.
+func ratable(game GameRecord) bool {
+	if game.Winner == "" || len(game.Participants) < 2 {
+		return false
+	}
+
+	for _, name := range game.Participants {
+		if name == game.Winner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ratingOf is name's rating, or where engine starts players who are yet to
+// play a game it could rate.
+func ratingOf(engine RatingEngine, ratings Ratings, name string) float64 {
+	if rating, ok := ratings[name]; ok {
+		return rating
+	}
+
+	return engine.Initial()
+}
+
+func RateGames(engine RatingEngine, games []GameRecord) Ratings {
+	ratings := make(Ratings)
+	for _, game := range inFinishingOrder(games) {
+		engine.Rate(ratings, game)
+	}
+
+	return ratings
+}
+
+// ratingsCache keeps the ratings from every game in a store until the store
+// says it has changed, so they aren't replayed on every request. Stores that
+// can't be watched are rated every time.
+type ratingsCache struct {
+	changes <-chan struct{}
+
+	mu      sync.Mutex
+	ratings Ratings
+}
+
+func newRatingsCache(store PlayerStore) *ratingsCache {
+	c := &ratingsCache{}
+	if watcher, ok := store.(LeagueWatcher); ok {
+		c.changes = watcher.WatchLeague()
+	}
+
+	return c
+}
+
+// get returns the cached ratings, or those from rate if the store has changed
+// since they were cached. Changes are signalled before a write returns, so a
+// read always sees the writes that came before it.
+func (c *ratingsCache) get(rate func() Ratings) Ratings {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.changes:
+		c.ratings = nil
+	default:
+	}
+
+	if c.ratings == nil || c.changes == nil {
+		c.ratings = rate()
+	}
+
+	return c.ratings
+}
+
+// reset forgets the cached ratings, for when they're rated another way.
+func (c *ratingsCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ratings = nil
+}
@@ -0,0 +1,105 @@
+package poker_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestElo(t *testing.T) {
+	elo := poker.Elo{K: 32, StartingRating: 1500}
+
+	t.Run("heads up game between equal players", func(t *testing.T) {
+		ratings := poker.Ratings{}
+		elo.Rate(ratings, poker.GameRecord{Participants: []string{"Chris", "Cleo"}, Winner: "Cleo"})
+
+		assertRatings(t, ratings, poker.Ratings{"Cleo": 1516, "Chris": 1484})
+	})
+
+	t.Run("K is shared between everyone the winner beat", func(t *testing.T) {
+		ratings := poker.Ratings{}
+		elo.Rate(ratings, poker.GameRecord{Participants: []string{"Chris", "Cleo", "Ruth"}, Winner: "Ruth"})
+
+		assertRatings(t, ratings, poker.Ratings{"Ruth": 1516, "Chris": 1492, "Cleo": 1492})
+	})
+
+	t.Run("an upset moves ratings more than an expected win", func(t *testing.T) {
+		ratings := poker.Ratings{"Chris": 1700, "Cleo": 1300}
+		elo.Rate(ratings, poker.GameRecord{Participants: []string{"Chris", "Cleo"}, Winner: "Cleo"})
+
+		if gain := ratings["Cleo"] - 1300; gain <= 16 {
+			t.Errorf("expected underdog to gain more than 16 but gained %v", gain)
+		}
+	})
+
+	unratable := []struct {
+		name string
+		game poker.GameRecord
+	}{
+		{"a game without opponents", poker.GameRecord{NumberOfPlayers: 5, Winner: "Cleo"}},
+		{"a game with a single participant", poker.GameRecord{Participants: []string{"Cleo"}, Winner: "Cleo"}},
+		{"a game without a winner", poker.GameRecord{Participants: []string{"Chris", "Cleo"}}},
+		{"a game won by someone who didn't play", poker.GameRecord{Participants: []string{"Chris", "Cleo"}, Winner: "Ruth"}},
+	}
+
+	for _, c := range unratable {
+		t.Run(c.name+" changes nothing", func(t *testing.T) {
+			ratings := poker.Ratings{"Chris": 1500}
+			elo.Rate(ratings, c.game)
+
+			assertRatings(t, ratings, poker.Ratings{"Chris": 1500})
+		})
+	}
+}
+
+func TestRateGames(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 22, 0, 0, 0, time.UTC)
+	}
+
+	games := []poker.GameRecord{
+		{FinishedAt: day(2), Participants: []string{"Chris", "Cleo"}, Winner: "Chris"},
+		{FinishedAt: day(1), Participants: []string{"Chris", "Cleo"}, Winner: "Cleo"},
+	}
+
+	got := poker.RateGames(poker.Elo{K: 32, StartingRating: 1500}, games)
+
+	if got["Chris"] <= got["Cleo"] {
+		t.Errorf("expected Chris's later win against a higher rated Cleo to put him ahead, got %v", got)
+	}
+
+	t.Run("deleting a winner leaves everyone else rated on the other games", func(t *testing.T) {
+		store := &poker.StubPlayerStore{Games: append([]poker.GameRecord{
+			{FinishedAt: day(3), Participants: []string{"Chris", "Cleo", "Pepper"}, Winner: "Pepper"},
+		}, games...)}
+		poker.AssertNoError(t, store.DeletePlayer("Pepper"))
+
+		assertRatings(t, poker.RateGames(poker.DefaultRatingEngine, store.Games), poker.RateGames(poker.DefaultRatingEngine, games))
+	})
+
+	t.Run("players without games get the starting rating", func(t *testing.T) {
+		standings := poker.NewStandings(poker.League{{"Pepper", 2}}, games, poker.Elo{K: 32, StartingRating: 1500})
+
+		for _, standing := range standings {
+			if standing.Name == "Pepper" && standing.Rating != 1500 {
+				t.Errorf("got rating %v for Pepper want 1500", standing.Rating)
+			}
+		}
+	})
+}
+
+func assertRatings(t testing.TB, got, want poker.Ratings) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got ratings %v want %v", got, want)
+	}
+
+	for name, rating := range want {
+		if math.Abs(got[name]-rating) > 0.001 {
+			t.Errorf("got rating %v for %s want %v", got[name], name, rating)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const seasonLayout = "2006-01"
+
+var (
+	ErrSeasonClosed  = errors.New("season is already closed")
+	ErrSeasonNotOver = errors.New("season isn't over yet")
+)
+
+// Season is a calendar month in UTC, covering games finished from From up to
+// but not including To. Closed seasons keep the League they finished with.
+type Season struct {
+	ID       string
+	From     time.Time
+	To       time.Time
+	ClosedAt time.Time
+	League   League `json:",omitempty"`
+}
+
+func SeasonOf(t time.Time) Season {
+	t = t.UTC()
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return Season{
+		ID:   from.Format(seasonLayout),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+func ParseSeason(id string) (Season, error) {
+	t, err := time.Parse(seasonLayout, id)
+	if err != nil {
+		return Season{}, fmt.Errorf("bad season %q, expected a month like %s", id, seasonLayout)
+	}
+
+	return SeasonOf(t), nil
+}
+
+// GamesBetween returns the games that finished in [from, to). A zero bound is
+// left open.
+func GamesBetween(games []GameRecord, from, to time.Time) []GameRecord {
+	var between []GameRecord
+
+	for _, game := range games {
+		if !from.IsZero() && game.FinishedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !game.FinishedAt.Before(to) {
+			continue
+		}
+		between = append(between, game)
+	}
+
+	return between
+}
+
+// LeagueFromGames counts a win for the winner of every game, sorted by wins.
+func LeagueFromGames(games []GameRecord) League {
+	league := League{}
+
+	for _, game := range inFinishingOrder(games) {
+		if game.Winner != "" {
+			league = league.withWin(game.Winner)
+		}
+	}
+
+	return league.sortedByWins()
+}
+
+// SeasonLeague returns the archived league of a closed season, or the
+// standings so far of an open one.
+func SeasonLeague(store PlayerStore, season Season) League {
+	if closed, ok := findSeason(store, season.ID); ok {
+		return closed.League
+	}
+
+	return LeagueFromGames(GamesBetween(store.GetGames(), season.From, season.To))
+}
+
+// CloseSeason archives the league of a season that has finished by now. Games
+// recorded afterwards no longer change it.
+func CloseSeason(store PlayerStore, id string, now time.Time) (Season, error) {
+	season, err := ParseSeason(id)
+	if err != nil {
+		return season, err
+	}
+
+	if season.To.After(now) {
+		return season, fmt.Errorf("%w: %s ends %s", ErrSeasonNotOver, id, season.To.Format(time.RFC3339))
+	}
+
+	season.League = SeasonLeague(store, season)
+	season.ClosedAt = now.UTC()
+
+	if err := store.ArchiveSeason(season); err != nil {
+		return season, err
+	}
+
+	return season, nil
+}
+
+func findSeason(store PlayerStore, id string) (Season, bool) {
+	return closedSeason(store.GetSeasons(), id)
+}
+
+// closedSeason finds season id among seasons, for stores to check a season
+// isn't archived twice.
+func closedSeason(seasons []Season, id string) (Season, bool) {
+	for _, season := range seasons {
+		if season.ID == id {
+			return season, true
+		}
+	}
+
+	return Season{}, false
+}
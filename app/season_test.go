@@ -0,0 +1,96 @@
+package poker_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestSeasons(t *testing.T) {
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 21, 0, 0, 0, time.UTC)
+	}
+
+	games := []poker.GameRecord{
+		{ID: "a", FinishedAt: at(9, 30), Winner: "Chris"},
+		{ID: "b", FinishedAt: at(10, 1), Winner: "Cleo"},
+		{ID: "c", FinishedAt: at(10, 15), Winner: "Cleo"},
+		{ID: "d", FinishedAt: at(10, 31), Winner: "Ruth"},
+		{ID: "e", FinishedAt: at(11, 1), Winner: "Ruth"},
+	}
+
+	t.Run("season of a time is its month", func(t *testing.T) {
+		got := poker.SeasonOf(at(10, 17))
+
+		if got.ID != "2026-10" || !got.From.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || !got.To.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("got season %+v", got)
+		}
+	})
+
+	t.Run("parse a season", func(t *testing.T) {
+		got, err := poker.ParseSeason("2026-10")
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(got, poker.SeasonOf(at(10, 1))) {
+			t.Errorf("got season %+v", got)
+		}
+
+		if _, err := poker.ParseSeason("October"); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("league of games in a window", func(t *testing.T) {
+		season := poker.SeasonOf(at(10, 1))
+		got := poker.LeagueFromGames(poker.GamesBetween(games, season.From, season.To))
+
+		poker.AssertLeague(t, got, poker.League{{"Cleo", 2}, {"Ruth", 1}})
+	})
+
+	t.Run("open ended windows", func(t *testing.T) {
+		got := poker.GamesBetween(games, at(10, 31), time.Time{})
+		if len(got) != 2 {
+			t.Errorf("got %d games from Oct 31 want 2", len(got))
+		}
+
+		got = poker.GamesBetween(games, time.Time{}, at(10, 1))
+		if len(got) != 1 {
+			t.Errorf("got %d games before Oct 1 want 1", len(got))
+		}
+	})
+
+	t.Run("closing a season archives its league", func(t *testing.T) {
+		store := &poker.StubPlayerStore{Games: append([]poker.GameRecord{}, games...)}
+
+		closed, err := poker.CloseSeason(store, "2026-10", at(11, 2))
+		poker.AssertNoError(t, err)
+
+		poker.AssertLeague(t, closed.League, poker.League{{"Cleo", 2}, {"Ruth", 1}})
+		if len(store.Seasons) != 1 || !store.Seasons[0].ClosedAt.Equal(at(11, 2)) {
+			t.Errorf("season not archived, got %+v", store.Seasons)
+		}
+
+		store.Games = append(store.Games, poker.GameRecord{ID: "late", FinishedAt: at(10, 20), Winner: "Ruth"})
+		poker.AssertLeague(t, poker.SeasonLeague(store, poker.SeasonOf(at(10, 1))), closed.League)
+
+		_, err = poker.CloseSeason(store, "2026-10", at(11, 3))
+		if !errors.Is(err, poker.ErrSeasonClosed) {
+			t.Errorf("got error %v want %v", err, poker.ErrSeasonClosed)
+		}
+	})
+
+	t.Run("seasons can't close before they end", func(t *testing.T) {
+		store := &poker.StubPlayerStore{Games: append([]poker.GameRecord{}, games...)}
+
+		_, err := poker.CloseSeason(store, "2026-10", at(10, 31))
+		if !errors.Is(err, poker.ErrSeasonNotOver) {
+			t.Errorf("got error %v want %v", err, poker.ErrSeasonNotOver)
+		}
+		if len(store.Seasons) != 0 {
+			t.Errorf("archived a season still being played, got %+v", store.Seasons)
+		}
+	})
+}
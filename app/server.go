@@ -1,12 +1,18 @@
 package poker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
-	"strconv"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -16,91 +22,394 @@ type Player struct {
 	Wins int
 }
 
+// PlayerStore implementations must be safe for concurrent use, as the
+// PlayerServer calls them from every request goroutine.
 type PlayerStore interface {
 	GetPlayerScore(name string) int
-	RecordWin(name string)
+	// RecordWin counts a win posted on its own, keeping when it was won in
+	// the history in the same write.
+	RecordWin(name string) error
 	GetLeague() League
+	// RecordGame keeps game in the history and counts a win for its
+	// winner, in a single write.
+	RecordGame(game GameRecord) error
+	GetGames() []GameRecord
+	ArchiveSeason(season Season) error
+	GetSeasons() []Season
+	SetPlayerScore(name string, wins int) error
+	DeletePlayer(name string) error
+	RenamePlayer(from, to string) error
+	MergePlayers(from, into string) error
 }
 
 type PlayerServer struct {
 	store    PlayerStore
-	template *template.Template
-	game     Game
+	pages    map[string]*template.Template
+	games    *GameManager
+	ratings  RatingEngine
+	rated    *ratingsCache
+	pongWait time.Duration
+	upgrader websocket.Upgrader
 	http.Handler
+
+	// tokens authenticate requests once auth is in use.
+	tokens   TokenStore
+	sessions *sessions
+
+	// assets are the pages and static files, read from assetsDir when
+	// it's set rather than the embedded ones.
+	assets    fs.FS
+	assetsDir string
+
+	// Websockets and event streams outlive their requests, so the server
+	// tracks them itself to shut down gracefully.
+	mu           sync.Mutex
+	shuttingDown bool
+	done         chan struct{}
+	streams      sync.WaitGroup
 }
 
 const htmlTemplatePath = "game.html"
 
-var wsUpgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-}
+// DefaultWSBufferSize is the size of the read and write buffers of each
+// websocket.
+const DefaultWSBufferSize = 1024
 
-func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
+func NewPlayerServer(store PlayerStore, games *GameManager) (*PlayerServer, error) {
 	server := new(PlayerServer)
 
-	tmpl, err := template.ParseFiles("game.html")
+	parsed, err := parsePages(embeddedAssets)
 	if err != nil {
-		return nil, fmt.Errorf("problem opening %s %v", htmlTemplatePath, err)
+		return nil, err
 	}
 
-	server.template = tmpl
+	server.pages = parsed
+	server.assets = embeddedAssets
 	server.store = store
-	server.game = game
+	server.games = games
+	server.ratings = DefaultRatingEngine
+	server.rated = newRatingsCache(store)
+	server.pongWait = DefaultPongWait
+	server.upgrader = websocket.Upgrader{
+		ReadBufferSize:  DefaultWSBufferSize,
+		WriteBufferSize: DefaultWSBufferSize,
+	}
+	server.done = make(chan struct{})
 
 	router := http.NewServeMux()
-	router.Handle("/league", http.HandlerFunc(server.leagueHandler))
+	router.Handle("/league", methods{http.MethodGet: server.leagueHandler})
+	router.Handle("/league/events", methods{http.MethodGet: server.leagueEventsHandler})
+	router.Handle("/league/ws", methods{http.MethodGet: server.leagueWebSocketHandler})
+	router.Handle("/players", methods{http.MethodGet: server.listPlayersHandler})
 	router.Handle("/players/", http.HandlerFunc(server.playersHandler))
-	router.Handle("/game", http.HandlerFunc(server.gameHandler))
-	router.Handle("/ws", http.HandlerFunc(server.webSocketHandler))
+	router.Handle("/games", methods{http.MethodGet: server.gamesHandler, http.MethodPost: server.openTableHandler})
+	router.Handle("/games/", http.HandlerFunc(server.tablesHandler))
+	router.Handle("/seasons", methods{http.MethodGet: server.seasonsHandler})
+	router.Handle("/seasons/", http.HandlerFunc(server.seasonHandler))
+	router.Handle("/game", methods{http.MethodGet: server.gameHandler})
+	router.Handle("/static/", methods{http.MethodGet: server.staticHandler})
+	router.Handle("/login", methods{http.MethodGet: server.loginHandler, http.MethodPost: server.loginHandler})
+	router.Handle("/logout", methods{http.MethodPost: server.logoutHandler})
+	router.Handle("/ws", methods{http.MethodGet: server.webSocketHandler})
 
-	server.Handler = router
+	server.Handler = server.authorize(router)
 
 	return server, nil
 }
 
+// UseRatingEngine changes how players are rated in the league and player
+// responses.
+func (s *PlayerServer) UseRatingEngine(engine RatingEngine) {
+	s.ratings = engine
+	s.rated.reset()
+}
+
+// allTimeRatings rates every game in the store, which is only done again once
+// the store changes.
+func (s *PlayerServer) allTimeRatings() Ratings {
+	return s.rated.get(func() Ratings {
+		return RateGames(s.ratings, s.store.GetGames())
+	})
+}
+
+// allTimeStandings is NewStandings for the whole league and every game.
+func (s *PlayerServer) allTimeStandings(league League, games []GameRecord) []PlayerStanding {
+	if s.ratings == nil {
+		return NewStandings(league, games, nil)
+	}
+
+	return standingsRated(league, games, s.ratings, s.allTimeRatings())
+}
+
+// UseHeartbeat changes how long websocket clients can go without answering a
+// ping before they're dropped.
+func (s *PlayerServer) UseHeartbeat(pongWait time.Duration) {
+	s.pongWait = pongWait
+}
+
+// UseWSBuffers changes the size of the read and write buffers of each
+// websocket.
+func (s *PlayerServer) UseWSBuffers(read, write int) {
+	s.upgrader.ReadBufferSize = read
+	s.upgrader.WriteBufferSize = write
+}
+
+// Shutdown tells the players at every table that the server is going away,
+// abandoning their games, and ends every websocket and event stream. It waits
+// for their handlers to finish until ctx is done. New websockets and streams
+// are refused from then on, but other requests are left to the http.Server.
+func (s *PlayerServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.shuttingDown {
+		s.shuttingDown = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	s.games.Shutdown()
+
+	finished := make(chan struct{})
+	go func() {
+		s.streams.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("problem shutting down player server, %v", ctx.Err())
+	}
+}
+
+// stream tracks a websocket or event stream until the returned func is
+// called, with a context that ends with the request or when the server shuts
+// down. Once the server is shutting down the request is refused and stream
+// returns false.
+func (s *PlayerServer) stream(w http.ResponseWriter, r *http.Request) (context.Context, func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return nil, nil, false
+	}
+	s.streams.Add(1)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		cancel()
+		s.streams.Done()
+	}, true
+}
+
+// recentGamesOnLeague is how many of the league's games its page lists.
+const recentGamesOnLeague = 10
+
 func (s *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
+	standings, games, err := s.standings(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Vary", "Accept")
+	if prefersHTML(r) {
+		if len(games) > recentGamesOnLeague {
+			games = games[:recentGamesOnLeague]
+		}
+
+		s.renderPage(w, http.StatusOK, "league.html", struct {
+			Season    string
+			Query     url.Values
+			Standings []PlayerStanding
+			Games     []GameRecord
+		}{r.URL.Query().Get("season"), r.URL.Query(), standings, games})
+		return
+	}
+
 	w.Header().Set("content-type", "application/json")
-	json.NewEncoder(w).Encode(s.store.GetLeague())
+	json.NewEncoder(w).Encode(standings)
 }
 
-func (s *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
-	player_name := strings.TrimPrefix(r.URL.Path, "/players/")
+// standings returns the league for a window, sorted as the query asks, and
+// the games played in it.
+func (s *PlayerServer) standings(query url.Values) ([]PlayerStanding, []GameRecord, error) {
+	league, games, err := s.leagueWindow(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var standings []PlayerStanding
+	if isAllTime(query) {
+		standings = s.allTimeStandings(league, games)
+	} else {
+		standings = NewStandings(league, games, s.ratings)
+	}
 
-	switch r.Method {
-	case http.MethodGet:
-		s.getScore(w, player_name)
-	case http.MethodPost:
-		s.postScore(w, player_name)
+	if err := sortStandingsBy(standings, query.Get("sort")); err != nil {
+		return nil, nil, err
 	}
+
+	return standings, games, nil
 }
 
-func (s *PlayerServer) gameHandler(w http.ResponseWriter, r *http.Request) {
-	s.template.Execute(w, nil)
+func sortStandingsBy(standings []PlayerStanding, by string) error {
+	switch by {
+	case "":
+	case SortByWins, SortByWinRate, SortByRating:
+		SortStandings(standings, by)
+	default:
+		return fmt.Errorf("unknown sort %q, expected %q, %q or %q", by, SortByWins, SortByWinRate, SortByRating)
+	}
+
+	return nil
+}
+
+// leagueWindow returns the league and played games for a season, a from/to
+// window, or all time when neither is asked for.
+func (s *PlayerServer) leagueWindow(query url.Values) (League, []GameRecord, error) {
+	if id := query.Get("season"); id != "" {
+		season, err := ParseSeason(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		return SeasonLeague(s.store, season), playedGames(GamesBetween(s.store.GetGames(), season.From, season.To)), nil
+	}
+
+	if query.Get("from") != "" || query.Get("to") != "" {
+		from, err := parseWindowTime(query.Get("from"))
+		if err != nil {
+			return nil, nil, err
+		}
+		to, err := parseWindowTime(query.Get("to"))
+		if err != nil {
+			return nil, nil, err
+		}
+		games := GamesBetween(s.store.GetGames(), from, to)
+		return LeagueFromGames(games), playedGames(games), nil
+	}
+
+	return s.store.GetLeague(), playedGames(s.store.GetGames()), nil
+}
+
+// isAllTime is whether query asks for the league of all time rather than a
+// season or window.
+func isAllTime(query url.Values) bool {
+	return query.Get("season") == "" && query.Get("from") == "" && query.Get("to") == ""
+}
+
+func parseWindowTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return t, fmt.Errorf("bad time %q, expected a date like 2006-01-02 or RFC 3339", value)
+	}
+
+	return t, nil
+}
+
+func (s *PlayerServer) seasonsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(s.store.GetSeasons())
 }
 
-func (s *PlayerServer) webSocketHandler(w http.ResponseWriter, r *http.Request) {
-	ws := newPlayerServerWS(w, r)
+func (s *PlayerServer) seasonHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/seasons/")
 
-	numberOfPlayersMsg := ws.WaitForMsg()
-	numberOfPlayers, _ := strconv.Atoi(numberOfPlayersMsg)
-	s.game.Start(numberOfPlayers, ws)
+	methods{
+		http.MethodGet:  func(w http.ResponseWriter, r *http.Request) { s.getSeason(w, id) },
+		http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.closeSeason(w, id) },
+	}.ServeHTTP(w, r)
+}
+
+func (s *PlayerServer) getSeason(w http.ResponseWriter, id string) {
+	season, err := ParseSeason(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if closed, ok := findSeason(s.store, id); ok {
+		season = closed
+	} else {
+		season.League = SeasonLeague(s.store, season)
+	}
 
-	winnerMsg := ws.WaitForMsg()
-	s.game.Finish(winnerMsg)
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(season)
 }
 
-func (s *PlayerServer) getScore(w http.ResponseWriter, player_name string) {
-	score := s.store.GetPlayerScore(player_name)
+func (s *PlayerServer) closeSeason(w http.ResponseWriter, id string) {
+	if _, err := ParseSeason(id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	season, err := CloseSeason(s.store, id, time.Now())
 
-	if score == 0 {
-		w.WriteHeader(http.StatusNotFound)
+	switch {
+	case errors.Is(err, ErrSeasonClosed), errors.Is(err, ErrSeasonNotOver):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	fmt.Fprint(w, score)
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(season)
 }
 
-func (s *PlayerServer) postScore(w http.ResponseWriter, player_name string) {
-	s.store.RecordWin(player_name)
-	w.WriteHeader(http.StatusAccepted)
+// methods routes a request by its method, answering anything else with 405
+// and the methods that are allowed. HEAD is answered by GET, as the server
+// leaves out the body.
+type methods map[string]http.HandlerFunc
+
+func (m methods) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.Method
+	if _, ok := m[method]; !ok && method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	if handler, ok := m[method]; ok {
+		handler(w, r)
+		return
+	}
+
+	allowed := make([]string, 0, len(m)+1)
+	for method := range m {
+		allowed = append(allowed, method)
+	}
+	if _, ok := m[http.MethodGet]; ok {
+		if _, ok := m[http.MethodHead]; !ok {
+			allowed = append(allowed, http.MethodHead)
+		}
+	}
+	sort.Strings(allowed)
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
 }
@@ -0,0 +1,262 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (s *PlayerServer) gamesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Vary", "Accept")
+	if prefersHTML(r) {
+		s.renderPage(w, http.StatusOK, "games.html", playedGames(s.store.GetGames()))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, playedGames(s.store.GetGames()))
+}
+
+// openTableHandler sets up a table that players join at /games/{id}/ws.
+func (s *PlayerServer) openTableHandler(w http.ResponseWriter, r *http.Request) {
+	table := s.games.Open()
+
+	w.Header().Set("Location", "/games/"+table.ID)
+	writeJSON(w, http.StatusCreated, table)
+}
+
+// tablesHandler serves the open tables at /games/active, and each table at
+// /games/{id} with its game played over /games/{id}/ws and watched over
+// /games/{id}/watch, or streamed as server-sent events from /games/{id}/events.
+func (s *PlayerServer) tablesHandler(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/games/"), "/")
+
+	switch {
+	case id == "active" && action == "":
+		methods{http.MethodGet: s.activeTablesHandler}.ServeHTTP(w, r)
+	case id != "" && action == "":
+		methods{http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+			s.getTable(w, id)
+		}}.ServeHTTP(w, r)
+	case id != "" && action == "ws":
+		methods{http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+			s.playTable(w, r, id)
+		}}.ServeHTTP(w, r)
+	case id != "" && action == "watch":
+		methods{http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+			s.watchTable(w, r, id)
+		}}.ServeHTTP(w, r)
+	case id != "" && action == "events":
+		methods{http.MethodGet: func(w http.ResponseWriter, r *http.Request) {
+			s.tableEvents(w, r, id)
+		}}.ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *PlayerServer) activeTablesHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.games.Tables())
+}
+
+func (s *PlayerServer) getTable(w http.ResponseWriter, id string) {
+	table, ok := s.games.Table(id)
+	if !ok {
+		http.Error(w, ErrTableNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, table)
+}
+
+func (s *PlayerServer) gameHandler(w http.ResponseWriter, r *http.Request) {
+	s.renderPage(w, http.StatusOK, htmlTemplatePath, struct{ BlindPresets []string }{BlindPresets()})
+}
+
+// webSocketHandler plays a game at a table of its own.
+func (s *PlayerServer) webSocketHandler(w http.ResponseWriter, r *http.Request) {
+	s.playTable(w, r, s.games.Open().ID)
+}
+
+// playTable plays the game at a table over the websocket protocol, sharing
+// what happens with the table's spectators. Players who lose their connection
+// can come back with the token they were seated with, catching up on the
+// latest blinds. The table is closed once the game is over, or abandoned
+// when they don't come back, and an abandoned game isn't recorded.
+func (s *PlayerServer) playTable(w http.ResponseWriter, r *http.Request, id string) {
+	_, done, ok := s.stream(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
+	table, err := s.games.sit(id, r.URL.Query().Get("token"))
+	switch {
+	case errors.Is(err, ErrTableNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	ws, err := newPlayerServerWS(w, r, &s.upgrader, s.pongWait)
+	if err != nil {
+		s.games.stand(id)
+		return
+	}
+
+	if err := ws.send(WSMessage{Type: SeatedMessage, GameID: id, Token: table.token}); err != nil {
+		ws.Close()
+		s.games.stand(id)
+		return
+	}
+
+	happened, messages := table.subscribe()
+	relayed := make(chan struct{})
+	go func() {
+		ws.relay(happened, messages)
+		close(relayed)
+	}()
+
+	if s.play(table, ws) {
+		s.games.Close(id)
+	} else {
+		table.hub.Unsubscribe(messages)
+		s.games.stand(id)
+	}
+	<-relayed
+}
+
+// play handles the players' messages until the game is over, returning false
+// if their connection went first.
+func (s *PlayerServer) play(table *table, ws *playerServerWS) bool {
+	id, game := table.ID, table.game
+	for {
+		msg, ok := ws.next()
+		if !ok {
+			return false
+		}
+
+		started := table.hasStarted()
+		switch {
+		case msg.Type == StartGameMessage && !started:
+			setup, err := msg.gameSetup(id)
+			if err == nil {
+				err = game.Start(table.ctx, setup, table)
+			}
+			if err != nil {
+				ws.sendError(err)
+				continue
+			}
+
+			s.games.started(id, setup)
+			table.publish(WSMessage{Type: GameStartedMessage, GameID: id, Players: setup.NumberOfPlayers, Participants: setup.Participants})
+
+		case msg.Type == ControlMessage && started:
+			command, _, ok := blindCommand(game, msg.Command)
+			if !ok {
+				ws.sendError(fmt.Errorf("unknown command %q", msg.Command))
+				continue
+			}
+
+			if err := command(); err != nil {
+				ws.sendError(err)
+				continue
+			}
+			table.publish(WSMessage{Type: ControlAppliedMessage, Command: msg.Command})
+
+		case msg.Type == DeclareWinnerMessage && started:
+			winner := strings.TrimSpace(msg.Winner)
+			if winner == "" {
+				ws.sendError(errors.New("a winner is required"))
+				continue
+			}
+
+			if err := game.Finish(winner); err != nil {
+				log.Printf("problem finishing game %v\n", err)
+				ws.sendError(err)
+				return true
+			}
+			table.publish(WSMessage{Type: GameOverMessage, GameID: id, Winner: winner})
+			return true
+
+		case msg.Type == StartGameMessage:
+			ws.sendError(errors.New("game already started"))
+
+		case msg.Type == ControlMessage || msg.Type == DeclareWinnerMessage:
+			ws.sendError(errors.New("game hasn't started"))
+
+		default:
+			ws.sendError(fmt.Errorf("unknown message type %q", msg.Type))
+		}
+	}
+}
+
+// watchTable lets a spectator follow the game at a table, starting with the
+// latest blinds, until the table closes.
+func (s *PlayerServer) watchTable(w http.ResponseWriter, r *http.Request, id string) {
+	_, done, ok := s.stream(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
+	table, leave, err := s.games.watch(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer leave()
+
+	ws, err := newPlayerServerWS(w, r, &s.upgrader, s.pongWait)
+	if err != nil {
+		return
+	}
+
+	happened, messages := table.subscribe()
+
+	left := ws.closedByPeer(errors.New("spectators can't send messages"))
+	go func() {
+		<-left
+		table.hub.Unsubscribe(messages)
+	}()
+
+	ws.relay(happened, messages)
+}
+
+// tableEvents streams the game at a table as server-sent events for clients
+// that can't use websockets. Clients that send a Last-Event-ID pick up from
+// that event, and the rest start with the latest blinds like spectators do.
+func (s *PlayerServer) tableEvents(w http.ResponseWriter, r *http.Request, id string) {
+	ctx, done, ok := s.stream(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
+	table, leave, err := s.games.watch(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer leave()
+
+	stream, err := newPlayerServerSSE(w)
+	if err != nil {
+		return
+	}
+
+	var happened []WSMessage
+	var messages <-chan WSMessage
+	if lastEventID, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		happened, messages = table.subscribeSince(lastEventID)
+	} else {
+		happened, messages = table.subscribe()
+	}
+	defer table.hub.Unsubscribe(messages)
+
+	stream.relay(ctx, happened, messages, s.pongWait*9/10)
+}
@@ -0,0 +1,534 @@
+package poker_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
+)
+
+func TestTables(t *testing.T) {
+	t.Run("open a table and find it among the active ones", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPost, "/games", ""))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusCreated)
+		table := getTableFromResponse(t, response.Body)
+		if got := response.Header().Get("Location"); got != "/games/"+table.ID {
+			t.Errorf("got location %q want /games/%s", got, table.ID)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/games/"+table.ID, ""))
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		if got := getTableFromResponse(t, response.Body); got.ID != table.ID || got.Playing {
+			t.Errorf("got table %+v want %+v", got, table)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/games/active", ""))
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+
+		var active []poker.Table
+		if err := json.NewDecoder(response.Body).Decode(&active); err != nil {
+			t.Fatalf("Unable to parse response from server into tables, %v", err)
+		}
+		if len(active) != 1 || active[0].ID != table.ID {
+			t.Errorf("got active tables %+v want just %s", active, table.ID)
+		}
+	})
+
+	t.Run("a table nobody sits at is closed", func(t *testing.T) {
+		games := poker.NewGameManager(func() poker.Game { return &GameSpy{} })
+		idle := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+		games.UseClock(idle)
+		server := httptest.NewServer(mustCreateTablesServer(t, &poker.StubPlayerStore{}, games))
+		defer server.Close()
+
+		response, err := http.Post(server.URL+"/games", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		unseated := games.Tables()[0]
+
+		seated, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/games/"+games.Open().ID+"/ws")
+		defer seated.Close()
+
+		idle.BlockUntil(1)
+		idle.Advance(poker.DefaultIdleTimeout)
+
+		if _, open := games.Table(unseated.ID); open {
+			t.Error("expected the table nobody sat at to be closed")
+		}
+		if tables := games.Tables(); len(tables) != 1 || !tables[0].Playing {
+			t.Errorf("got active tables %+v want just the seated one", tables)
+		}
+	})
+
+	t.Run("an unknown table is not found", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		for _, path := range []string{"/games/nope", "/games/nope/ws", "/games/nope/other"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodGet, path, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("a table only seats one connection of players", func(t *testing.T) {
+		game := &GameSpy{}
+		games := poker.NewGameManager(func() poker.Game { return game })
+		server := httptest.NewServer(mustCreateTablesServer(t, &poker.StubPlayerStore{}, games))
+		defer server.Close()
+
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/" + games.Open().ID + "/ws"
+		ws, _ := mustSitWS(t, wsURL)
+		defer ws.Close()
+
+		_, response, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil || response.StatusCode != http.StatusConflict {
+			t.Errorf("expected a second connection to be refused with 409, got %v", err)
+		}
+	})
+
+	t.Run("players who stop answering pings lose their seat", func(t *testing.T) {
+		games := poker.NewGameManager(func() poker.Game { return &GameSpy{} })
+		seats := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+		games.UseClock(seats)
+		tables := mustCreateTablesServer(t, &poker.StubPlayerStore{}, games)
+		tables.UseHeartbeat(20 * time.Millisecond)
+		server := httptest.NewServer(tables)
+		defer server.Close()
+
+		answering, silent := games.Open(), games.Open()
+		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/"
+
+		// Reading answers pings.
+		ws, _ := mustSitWS(t, wsURL+answering.ID+"/ws")
+		defer ws.Close()
+		go func() {
+			for {
+				if _, _, err := ws.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		quiet, _ := mustSitWS(t, wsURL+silent.ID+"/ws")
+		defer quiet.Close()
+
+		// Standing up starts the wait for the players to come back.
+		seats.BlockUntil(1)
+		if got, _ := games.Table(silent.ID); got.Playing {
+			t.Error("expected a client that doesn't answer pings to lose its seat")
+		}
+		if got, _ := games.Table(answering.ID); !got.Playing {
+			t.Error("expected a client that answers pings to keep its seat")
+		}
+	})
+
+	t.Run("tables play independently and close when their game is over", func(t *testing.T) {
+		fake := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+		store := &lockedStore{StubPlayerStore: &poker.StubPlayerStore{}}
+		games := poker.NewGameManager(func() poker.Game {
+			return poker.NewTexasHoldem(fake, store, standardBlinds)
+		})
+		server := httptest.NewServer(mustCreateTablesServer(t, store, games))
+		defer server.Close()
+
+		first, second := games.Open(), games.Open()
+		firstWS, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/games/"+first.ID+"/ws")
+		defer firstWS.Close()
+		secondWS, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/games/"+second.ID+"/ws")
+		defer secondWS.Close()
+
+		sendWS(t, firstWS, poker.WSMessage{Type: poker.StartGameMessage, Players: 5})
+		sendWS(t, secondWS, poker.WSMessage{Type: poker.StartGameMessage, Players: 5})
+		assertWSMessage(t, firstWS, poker.WSMessage{Type: poker.GameStartedMessage, GameID: first.ID, Players: 5})
+		assertWSMessage(t, secondWS, poker.WSMessage{Type: poker.GameStartedMessage, GameID: second.ID, Players: 5})
+
+		fake.BlockUntil(22)
+		fake.Advance(0)
+		assertSmallBlind(t, firstWS, 100)
+		assertSmallBlind(t, secondWS, 100)
+
+		sendWS(t, firstWS, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: "Ruth"})
+		assertWSMessage(t, firstWS, poker.WSMessage{Type: poker.GameOverMessage, GameID: first.ID, Winner: "Ruth"})
+		if _, _, err := firstWS.ReadMessage(); err == nil {
+			t.Fatal("expected the first table's connection to close once its game finished")
+		}
+		if _, open := games.Table(first.ID); open {
+			t.Fatal("expected the first table to close once its game finished")
+		}
+
+		fake.Advance(10 * time.Minute)
+		assertSmallBlind(t, secondWS, 200)
+
+		if tables := games.Tables(); len(tables) != 1 || tables[0].ID != second.ID || tables[0].NumberOfPlayers != 5 {
+			t.Errorf("got active tables %+v want just %s with 5 players", tables, second.ID)
+		}
+
+		if recorded := store.GetGames(); len(recorded) != 1 || recorded[0].ID != first.ID || recorded[0].Winner != "Ruth" {
+			t.Errorf("got games %+v want the first table won by Ruth", recorded)
+		}
+	})
+}
+
+func TestSpectators(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+	store := &lockedStore{StubPlayerStore: &poker.StubPlayerStore{}}
+	games := poker.NewGameManager(func() poker.Game {
+		return poker.NewTexasHoldem(fake, store, standardBlinds)
+	})
+	server := httptest.NewServer(mustCreateTablesServer(t, store, games))
+	defer server.Close()
+
+	table := games.Open()
+	tableURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/" + table.ID
+
+	player, _ := mustSitWS(t, tableURL+"/ws")
+	defer player.Close()
+
+	sendWS(t, player, poker.WSMessage{Type: poker.StartGameMessage, Participants: []string{"Chris", "Ruth"}})
+	started := poker.WSMessage{Type: poker.GameStartedMessage, GameID: table.ID, Players: 2, Participants: []string{"Chris", "Ruth"}}
+	assertWSMessage(t, player, started)
+
+	fake.BlockUntil(11)
+	fake.Advance(0)
+	assertSmallBlind(t, player, 100)
+
+	t.Run("spectators join with the latest blinds", func(t *testing.T) {
+		spectator := mustDialWS(t, tableURL+"/watch")
+		defer spectator.Close()
+
+		assertWSMessage(t, spectator, started)
+		assertSmallBlind(t, spectator, 100)
+
+		if got, _ := games.Table(table.ID); got.Spectators != 1 {
+			t.Error("expected the table to count its spectator")
+		}
+	})
+
+	t.Run("every client gets the blinds and the result", func(t *testing.T) {
+		tv, phone := mustDialWS(t, tableURL+"/watch"), mustDialWS(t, tableURL+"/watch")
+		defer tv.Close()
+		defer phone.Close()
+
+		for _, spectator := range []*websocket.Conn{tv, phone} {
+			readWS(t, spectator)
+			readWS(t, spectator)
+		}
+
+		fake.Advance(7 * time.Minute)
+		for _, client := range []*websocket.Conn{player, tv, phone} {
+			assertSmallBlind(t, client, 200)
+		}
+
+		sendWS(t, player, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: "Ruth"})
+		for _, client := range []*websocket.Conn{player, tv, phone} {
+			assertWSMessage(t, client, poker.WSMessage{Type: poker.GameOverMessage, GameID: table.ID, Winner: "Ruth"})
+
+			if _, _, err := client.ReadMessage(); err == nil {
+				t.Error("expected the connection to close once the game was over")
+			}
+		}
+	})
+
+	t.Run("a closed table can't be watched", func(t *testing.T) {
+		_, response, err := websocket.DefaultDialer.Dial(tableURL+"/watch", nil)
+		if err == nil || response.StatusCode != http.StatusNotFound {
+			t.Errorf("expected watching a closed table to be refused with 404, got %v", err)
+		}
+	})
+}
+
+func TestReconnect(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+	store := &lockedStore{StubPlayerStore: &poker.StubPlayerStore{}}
+	games := poker.NewGameManager(func() poker.Game {
+		return poker.NewTexasHoldem(fake, store, standardBlinds)
+	})
+	seats := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+	games.UseClock(seats)
+	server := httptest.NewServer(mustCreateTablesServer(t, store, games))
+	defer server.Close()
+
+	table := games.Open()
+	playURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/" + table.ID + "/ws"
+
+	player, token := mustSitWS(t, playURL)
+	sendWS(t, player, poker.WSMessage{Type: poker.StartGameMessage, Players: 2})
+	started := poker.WSMessage{Type: poker.GameStartedMessage, GameID: table.ID, Players: 2}
+	assertWSMessage(t, player, started)
+
+	fake.BlockUntil(11)
+	fake.Advance(0)
+	assertSmallBlind(t, player, 100)
+
+	player.Close()
+	seats.BlockUntil(1)
+	if got, _ := games.Table(table.ID); got.Playing {
+		t.Fatal("expected the dropped players to lose their seat")
+	}
+
+	for _, url := range []string{playURL, playURL + "?token=wrong"} {
+		_, response, err := websocket.DefaultDialer.Dial(url, nil)
+		if err == nil || response.StatusCode != http.StatusConflict {
+			t.Errorf("expected %s to be refused with 409, got %v", url, err)
+		}
+	}
+
+	player, _ = mustSitWS(t, playURL+"?token="+token)
+	defer player.Close()
+
+	assertWSMessage(t, player, started)
+	assertSmallBlind(t, player, 100)
+
+	fake.Advance(7 * time.Minute)
+	assertSmallBlind(t, player, 200)
+
+	sendWS(t, player, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: "Ruth"})
+	assertWSMessage(t, player, poker.WSMessage{Type: poker.GameOverMessage, GameID: table.ID, Winner: "Ruth"})
+
+	if len(store.GetGames()) != 1 {
+		t.Errorf("expected the reconnected game to be recorded, got %+v", store.GetGames())
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+	store := &lockedStore{StubPlayerStore: &poker.StubPlayerStore{}}
+	games := poker.NewGameManager(func() poker.Game {
+		return poker.NewTexasHoldem(fake, store, standardBlinds)
+	})
+	server := httptest.NewServer(mustCreateTablesServer(t, store, games))
+	defer server.Close()
+
+	table := games.Open()
+	eventsURL := server.URL + "/games/" + table.ID + "/events"
+
+	player, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/games/"+table.ID+"/ws")
+	defer player.Close()
+
+	sendWS(t, player, poker.WSMessage{Type: poker.StartGameMessage, Players: 2})
+	readWS(t, player)
+
+	fake.BlockUntil(11)
+	fake.Advance(0)
+	assertSmallBlind(t, player, 100)
+
+	t.Run("streams the game as it's played", func(t *testing.T) {
+		events := mustStreamEvents(t, eventsURL, "")
+		defer events.Close()
+
+		assertEvent(t, events, "1", poker.GameStartedMessage)
+		assertEvent(t, events, "2", poker.BlindUpdateMessage)
+
+		fake.Advance(7 * time.Minute)
+		if got := assertEvent(t, events, "3", poker.BlindUpdateMessage); got.Blind == nil || got.Blind.SmallBlind != 200 {
+			t.Errorf("got %+v want a blind update to 200", got)
+		}
+	})
+
+	t.Run("resumes after the last event seen", func(t *testing.T) {
+		events := mustStreamEvents(t, eventsURL, "1")
+		defer events.Close()
+
+		assertEvent(t, events, "2", poker.BlindUpdateMessage)
+		assertEvent(t, events, "3", poker.BlindUpdateMessage)
+
+		sendWS(t, player, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: "Ruth"})
+		if got := assertEvent(t, events, "4", poker.GameOverMessage); got.Winner != "Ruth" {
+			t.Errorf("got %+v want Ruth to have won", got)
+		}
+
+		if _, ok := <-events.lines; ok {
+			t.Error("expected the stream to end once the game was over")
+		}
+	})
+
+	t.Run("an unknown table has no events", func(t *testing.T) {
+		response, err := http.Get(server.URL + "/games/nope/events")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		poker.AssertResponseStatus(t, response.StatusCode, http.StatusNotFound)
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	game := &GameSpy{}
+	games := poker.NewGameManager(func() poker.Game { return game })
+	tables := mustCreateTablesServer(t, poker.NewNotifyingStore(&poker.StubPlayerStore{}), games)
+	server := httptest.NewServer(tables)
+	defer server.Close()
+
+	player, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/games/"+games.Open().ID+"/ws")
+	defer player.Close()
+	sendWS(t, player, poker.WSMessage{Type: poker.StartGameMessage, Players: 3})
+	readWS(t, player)
+
+	league := mustStreamEvents(t, server.URL+"/league/events", "")
+	defer league.Close()
+	assertEvent(t, league, "", poker.LeagueUpdateMessage)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	poker.AssertNoError(t, tables.Shutdown(ctx))
+
+	assertWSMessage(t, player, poker.WSMessage{Type: poker.ServerClosingMessage})
+	if _, _, err := player.ReadMessage(); err == nil {
+		t.Error("expected the player's connection to close")
+	}
+
+	game.mu.Lock()
+	gameCtx := game.StartedContext
+	game.mu.Unlock()
+	if gameCtx.Err() == nil {
+		t.Error("expected the game to be abandoned")
+	}
+
+	timeout := time.After(time.Second)
+	for ended := false; !ended; {
+		select {
+		case _, open := <-league.lines:
+			ended = !open
+		case <-timeout:
+			t.Fatal("expected the league stream to end")
+		}
+	}
+
+	response, err := http.Get(server.URL + "/league/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	poker.AssertResponseStatus(t, response.StatusCode, http.StatusServiceUnavailable)
+}
+
+type eventStream struct {
+	io.Closer
+	lines chan string
+}
+
+func mustStreamEvents(t *testing.T, url, lastEventID string) eventStream {
+	t.Helper()
+
+	request, _ := http.NewRequest(http.MethodGet, url, nil)
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("could not stream events from %s %v", url, err)
+	}
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("got content type %q want text/event-stream", got)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	return eventStream{Closer: response.Body, lines: lines}
+}
+
+// assertEvent reads the next event, skipping heartbeats, and checks its id
+// and type before returning its data.
+func assertEvent(t *testing.T, events eventStream, wantID, wantType string) poker.WSMessage {
+	t.Helper()
+
+	fields := map[string]string{}
+	for {
+		select {
+		case line, ok := <-events.lines:
+			if !ok {
+				t.Fatalf("stream ended waiting for event %s", wantID)
+			}
+
+			if line == "" && len(fields) > 0 {
+				var msg poker.WSMessage
+				if err := json.Unmarshal([]byte(fields["data"]), &msg); err != nil {
+					t.Fatalf("could not parse event data %q, %v", fields["data"], err)
+				}
+				if fields["id"] != wantID || fields["event"] != wantType || msg.Type != wantType {
+					t.Errorf("got event %v want %s with id %s", fields, wantType, wantID)
+				}
+				return msg
+			}
+
+			if name, value, ok := strings.Cut(line, ": "); ok && name != "" {
+				fields[name] = value
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %s", wantID)
+		}
+	}
+}
+
+func assertSmallBlind(t *testing.T, ws *websocket.Conn, want int) {
+	t.Helper()
+
+	got := readWS(t, ws)
+	if got.Type != poker.BlindUpdateMessage || got.Blind == nil || got.Blind.SmallBlind != want {
+		t.Errorf("got %+v want a blind update to %d", got, want)
+	}
+}
+
+func getTableFromResponse(t testing.TB, body *bytes.Buffer) poker.Table {
+	t.Helper()
+
+	var table poker.Table
+	if err := json.NewDecoder(body).Decode(&table); err != nil {
+		t.Fatalf("Unable to parse response from server %q into a table, %v", body, err)
+	}
+
+	return table
+}
+
+// lockedStore lets tables played at the same time share a StubPlayerStore.
+type lockedStore struct {
+	mu sync.Mutex
+	*poker.StubPlayerStore
+}
+
+func (s *lockedStore) RecordWin(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StubPlayerStore.RecordWin(name)
+}
+
+func (s *lockedStore) RecordGame(game poker.GameRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StubPlayerStore.RecordGame(game)
+}
+
+func (s *lockedStore) GetGames() []poker.GameRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.StubPlayerStore.GetGames()
+}
@@ -27,7 +27,7 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 		response := httptest.NewRecorder()
 		server.ServeHTTP(response, newGetScoreRequest(player))
 		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
-		poker.AssertResponseBody(t, response.Body.String(), "3")
+		assertPlayerWins(t, getPlayerFromResponse(t, response.Body), player, 3)
 	})
 
 	t.Run("get league", func(t *testing.T) {
@@ -0,0 +1,118 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+var errNoLeagueUpdates = errors.New("live league updates aren't available from this store")
+
+// leagueEventsHandler streams the league, as /league would answer the same
+// query, as server-sent events whenever it changes.
+func (s *PlayerServer) leagueEventsHandler(w http.ResponseWriter, r *http.Request) {
+	watcher, ok := s.leagueWatcher(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	ctx, done, ok := s.stream(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
+	stream, err := newPlayerServerSSE(w)
+	if err != nil {
+		return
+	}
+
+	updates := s.leagueUpdates(ctx, r.URL.Query(), watcher)
+	stream.relay(ctx, nil, updates, s.pongWait*9/10)
+}
+
+// leagueWebSocketHandler is leagueEventsHandler for websocket clients.
+func (s *PlayerServer) leagueWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	watcher, ok := s.leagueWatcher(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	ctx, done, ok := s.stream(w, r)
+	if !ok {
+		return
+	}
+	defer done()
+
+	ws, err := newPlayerServerWS(w, r, &s.upgrader, s.pongWait)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	left := ws.closedByPeer(errors.New("league watchers can't send messages"))
+	go func() {
+		<-left
+		cancel()
+	}()
+
+	ws.relay(nil, s.leagueUpdates(ctx, r.URL.Query(), watcher))
+}
+
+// leagueWatcher checks the store can tell when the league changes and that
+// the query is one /league would answer, replying with an error when not.
+func (s *PlayerServer) leagueWatcher(w http.ResponseWriter, query url.Values) (LeagueWatcher, bool) {
+	watcher, ok := s.store.(LeagueWatcher)
+	if !ok {
+		http.Error(w, errNoLeagueUpdates.Error(), http.StatusNotImplemented)
+		return nil, false
+	}
+
+	if _, _, err := s.standings(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return watcher, true
+}
+
+// leagueUpdates sends the standings for query now and after every change to
+// the league, until ctx is done.
+func (s *PlayerServer) leagueUpdates(ctx context.Context, query url.Values, watcher LeagueWatcher) <-chan WSMessage {
+	changes := watcher.WatchLeague()
+	updates := make(chan WSMessage)
+
+	go func() {
+		defer close(updates)
+		defer watcher.UnwatchLeague(changes)
+
+		for {
+			standings, _, err := s.standings(query)
+			if err != nil {
+				log.Printf("problem getting league standings %v\n", err)
+				return
+			}
+
+			select {
+			case updates <- WSMessage{Version: WSProtocolVersion, Type: LeagueUpdateMessage, Standings: standings}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case _, ok := <-changes:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
@@ -0,0 +1,143 @@
+package poker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestLiveLeague(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+	fileStore, err := poker.NewFileSystemStore(database)
+	poker.AssertNoError(t, err)
+
+	store := poker.NewNotifyingStore(fileStore)
+	server := httptest.NewServer(mustCreatePlayerServer(t, store, dummyGame))
+	defer server.Close()
+
+	recordWin := func(t *testing.T, name string) {
+		t.Helper()
+
+		response, err := http.Post(server.URL+"/players/"+name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	t.Run("event stream gets the league and every change to it", func(t *testing.T) {
+		events := mustStreamEvents(t, server.URL+"/league/events", "")
+		defer events.Close()
+
+		if got := assertEvent(t, events, "", poker.LeagueUpdateMessage); len(got.Standings) != 0 {
+			t.Errorf("got standings %+v want an empty league", got.Standings)
+		}
+
+		recordWin(t, "Pepper")
+		assertStandings(t, assertEvent(t, events, "", poker.LeagueUpdateMessage), poker.Player{Name: "Pepper", Wins: 1})
+	})
+
+	t.Run("websocket gets the league and every change to it", func(t *testing.T) {
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/league/ws")
+		defer ws.Close()
+
+		assertStandings(t, readWS(t, ws), poker.Player{Name: "Pepper", Wins: 1})
+
+		recordWin(t, "Chris")
+		assertStandings(t, readWS(t, ws), poker.Player{Name: "Pepper", Wins: 1}, poker.Player{Name: "Chris", Wins: 1})
+	})
+
+	t.Run("the query is checked like /league", func(t *testing.T) {
+		response, err := http.Get(server.URL + "/league/events?sort=luck")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		poker.AssertResponseStatus(t, response.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("stores that can't be watched have no live league", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		for _, path := range []string{"/league/events", "/league/ws"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodGet, path, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusNotImplemented)
+		}
+	})
+}
+
+func TestNotifyingStore(t *testing.T) {
+	t.Run("only successful writes are changes", func(t *testing.T) {
+		store := poker.NewNotifyingStore(&poker.StubPlayerStore{})
+		changes := store.WatchLeague()
+
+		if err := store.DeletePlayer("Nobody"); err == nil {
+			t.Fatal("expected deleting an unknown player to fail")
+		}
+		poker.AssertNoError(t, store.SetPlayerScore("Pepper", 3))
+		store.UnwatchLeague(changes)
+
+		var got int
+		for range changes {
+			got++
+		}
+		if got != 1 {
+			t.Errorf("got %d changes want 1 for the one successful write", got)
+		}
+	})
+
+	t.Run("a burst of writes is one change and the watcher stays", func(t *testing.T) {
+		store := poker.NewNotifyingStore(&poker.StubPlayerStore{})
+		changes := store.WatchLeague()
+		defer store.UnwatchLeague(changes)
+
+		for i := 0; i < 20; i++ {
+			poker.AssertNoError(t, store.RecordWin("Pepper"))
+		}
+
+		assertChanged(t, changes, true)
+		assertChanged(t, changes, false)
+
+		poker.AssertNoError(t, store.RecordWin("Pepper"))
+		assertChanged(t, changes, true)
+	})
+}
+
+func assertChanged(t *testing.T, changes <-chan struct{}, want bool) {
+	t.Helper()
+
+	select {
+	case _, ok := <-changes:
+		if !ok {
+			t.Fatal("expected the watcher to stay subscribed")
+		}
+		if !want {
+			t.Error("got a change want none pending")
+		}
+	default:
+		if want {
+			t.Error("got no change want one pending")
+		}
+	}
+}
+
+func assertStandings(t *testing.T, got poker.WSMessage, want ...poker.Player) {
+	t.Helper()
+
+	var players []poker.Player
+	for _, standing := range got.Standings {
+		players = append(players, standing.Player)
+	}
+
+	if got.Type != poker.LeagueUpdateMessage || !reflect.DeepEqual(players, want) {
+		t.Errorf("got %+v want a league update with %v", got, want)
+	}
+}
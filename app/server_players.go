@@ -0,0 +1,252 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPlayersPerPage = 50
+	maxPlayersPerPage     = 500
+)
+
+type PlayersPage struct {
+	Players []PlayerStanding
+	Page    int
+	PerPage int
+	Total   int
+}
+
+type playerScoreRequest struct {
+	Wins *int
+}
+
+type playerRenameRequest struct {
+	Name string
+}
+
+type playerMergeRequest struct {
+	From string
+}
+
+func (s *PlayerServer) listPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, err := positiveQueryInt(query.Get("page"), 1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("bad page, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	perPage, err := positiveQueryInt(query.Get("per_page"), defaultPlayersPerPage)
+	if err != nil || perPage > maxPlayersPerPage {
+		http.Error(w, fmt.Sprintf("bad per_page, expected 1 to %d", maxPlayersPerPage), http.StatusBadRequest)
+		return
+	}
+
+	standings := s.allTimeStandings(s.store.GetLeague(), s.store.GetGames())
+	if err := sortStandingsBy(standings, query.Get("sort")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := PlayersPage{Players: []PlayerStanding{}, Page: page, PerPage: perPage, Total: len(standings)}
+	if start := (page - 1) * perPage; start < len(standings) {
+		end := start + perPage
+		if end > len(standings) {
+			end = len(standings)
+		}
+		result.Players = standings[start:end]
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
+	escaped, action, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/players/"), "/")
+
+	player_name, err := url.PathUnescape(escaped)
+	if err != nil || player_name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch action {
+	case "":
+		methods{
+			http.MethodGet:    func(w http.ResponseWriter, r *http.Request) { s.getPlayer(w, r, player_name) },
+			http.MethodPost:   func(w http.ResponseWriter, r *http.Request) { s.postScore(w, player_name) },
+			http.MethodPut:    func(w http.ResponseWriter, r *http.Request) { s.putScore(w, r, player_name) },
+			http.MethodPatch:  func(w http.ResponseWriter, r *http.Request) { s.renamePlayer(w, r, player_name) },
+			http.MethodDelete: func(w http.ResponseWriter, r *http.Request) { s.deletePlayer(w, player_name) },
+		}.ServeHTTP(w, r)
+	case "merge":
+		methods{
+			http.MethodPost: func(w http.ResponseWriter, r *http.Request) { s.mergePlayer(w, r, player_name) },
+		}.ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *PlayerServer) getPlayer(w http.ResponseWriter, r *http.Request, player_name string) {
+	standing, ok := s.findStanding(player_name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%v: %s", ErrPlayerNotFound, player_name), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Vary", "Accept")
+	if prefersHTML(r) {
+		s.renderPage(w, http.StatusOK, "player.html", struct {
+			Standing PlayerStanding
+			Games    []GameRecord
+		}{standing, gamesWith(s.store.GetGames(), player_name)})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, standing)
+}
+
+// gamesWith returns the games player_name won or took part in, leaving out
+// wins posted on their own.
+func gamesWith(games []GameRecord, player_name string) []GameRecord {
+	var with []GameRecord
+	for _, game := range games {
+		if game.isPostedWin() {
+			continue
+		}
+		for _, player := range playersIn(game) {
+			if player == player_name {
+				with = append(with, game)
+				break
+			}
+		}
+	}
+
+	return with
+}
+
+func (s *PlayerServer) postScore(w http.ResponseWriter, player_name string) {
+	if err := s.store.RecordWin(player_name); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *PlayerServer) putScore(w http.ResponseWriter, r *http.Request, player_name string) {
+	var body playerScoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Wins == nil || *body.Wins < 0 {
+		http.Error(w, `expected a body like {"Wins": 3}`, http.StatusBadRequest)
+		return
+	}
+
+	_, existed := s.findStanding(player_name)
+
+	if err := s.store.SetPlayerScore(player_name, *body.Wins); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if !existed {
+		status = http.StatusCreated
+	}
+
+	s.writeStanding(w, status, player_name)
+}
+
+func (s *PlayerServer) renamePlayer(w http.ResponseWriter, r *http.Request, player_name string) {
+	var body playerRenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		http.Error(w, `expected a body like {"Name": "Chris"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.RenamePlayer(player_name, body.Name); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	s.writeStanding(w, http.StatusOK, body.Name)
+}
+
+func (s *PlayerServer) mergePlayer(w http.ResponseWriter, r *http.Request, player_name string) {
+	var body playerMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.From == "" || body.From == player_name {
+		http.Error(w, `expected a body naming another player like {"From": "chris"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.MergePlayers(body.From, player_name); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	s.writeStanding(w, http.StatusOK, player_name)
+}
+
+func (s *PlayerServer) deletePlayer(w http.ResponseWriter, player_name string) {
+	if err := s.store.DeletePlayer(player_name); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findStanding works out a player's standing from just the games they played,
+// with their rating from every game.
+func (s *PlayerServer) findStanding(player_name string) (PlayerStanding, bool) {
+	player := s.store.GetLeague().Find(player_name)
+	games := gamesWith(s.store.GetGames(), player_name)
+	if player == nil && len(games) == 0 {
+		return PlayerStanding{}, false
+	}
+
+	standing := PlayerStanding{Player: Player{Name: player_name}, PlayerStats: statsFromGames(games)[player_name]}
+	if player != nil {
+		standing.Player = *player
+	}
+	if s.ratings != nil {
+		standing.Rating = ratingOf(s.ratings, s.allTimeRatings(), player_name)
+	}
+
+	return standing, true
+}
+
+func (s *PlayerServer) writeStanding(w http.ResponseWriter, status int, player_name string) {
+	standing, _ := s.findStanding(player_name)
+	writeJSON(w, status, standing)
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrPlayerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrPlayerExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func positiveQueryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("expected a positive number, got %q", value)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,248 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestListPlayers(t *testing.T) {
+	store := poker.StubPlayerStore{League: poker.League{{"Cleo", 5}, {"Chris", 4}, {"Ruth", 3}, {"Pepper", 2}, {"Floyd", 1}}}
+	server := mustCreatePlayerServer(t, &store, dummyGame)
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Cleo", "Chris", "Ruth", "Pepper", "Floyd"}},
+		{"per_page=2", []string{"Cleo", "Chris"}},
+		{"per_page=2&page=3", []string{"Floyd"}},
+		{"per_page=2&page=4", []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/players?"+c.query, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+
+			var got poker.PlayersPage
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatalf("Unable to parse response from server into a page of players, %v", err)
+			}
+
+			if got.Total != 5 {
+				t.Errorf("got total %d want 5", got.Total)
+			}
+
+			names := []string{}
+			for _, player := range got.Players {
+				names = append(names, player.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(c.want, ",") {
+				t.Errorf("got players %v want %v", names, c.want)
+			}
+		})
+	}
+
+	for _, query := range []string{"page=0", "per_page=many", "per_page=1000"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/players?"+query, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestEditPlayers(t *testing.T) {
+	newServer := func(t *testing.T) (*poker.StubPlayerStore, *poker.PlayerServer) {
+		store := &poker.StubPlayerStore{
+			League: poker.League{{"Cleo", 5}, {"Chris", 4}, {"chris", 2}},
+			Games:  []poker.GameRecord{{ID: "a", Participants: []string{"chris", "Cleo"}, Winner: "chris"}},
+		}
+		return store, mustCreatePlayerServer(t, store, dummyGame)
+	}
+
+	t.Run("PUT sets the wins of a new player", func(t *testing.T) {
+		store, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPut, "/players/Ruth", `{"Wins": 7}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusCreated)
+		assertPlayerWins(t, getPlayerFromResponse(t, response.Body), "Ruth", 7)
+		assertScoreInLeague(t, store.League, "Ruth", 7)
+	})
+
+	t.Run("PUT sets the wins of an existing player", func(t *testing.T) {
+		store, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPut, "/players/Cleo", `{"Wins": 0}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		assertScoreInLeague(t, store.League, "Cleo", 0)
+	})
+
+	for _, body := range []string{``, `{}`, `{"Wins": -1}`, `7`} {
+		t.Run("PUT rejects "+body, func(t *testing.T) {
+			_, server := newServer(t)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodPut, "/players/Cleo", body))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("PATCH renames a player", func(t *testing.T) {
+		store, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPatch, "/players/Cleo", `{"Name": "Cleopatra"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		assertPlayerWins(t, getPlayerFromResponse(t, response.Body), "Cleopatra", 5)
+		if store.League.Find("Cleo") != nil {
+			t.Error("expected Cleo to be renamed")
+		}
+		if store.Games[0].Participants[1] != "Cleopatra" {
+			t.Errorf("expected games to be renamed too, got %+v", store.Games[0])
+		}
+	})
+
+	t.Run("PATCH won't rename onto another player", func(t *testing.T) {
+		_, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPatch, "/players/chris", `{"Name": "Chris"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("PATCH a missing player", func(t *testing.T) {
+		_, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPatch, "/players/Nobody", `{"Name": "Somebody"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("merge a duplicate player", func(t *testing.T) {
+		store, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPost, "/players/Chris/merge", `{"From": "chris"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		got := getPlayerFromResponse(t, response.Body)
+		assertPlayerWins(t, got, "Chris", 6)
+		if got.GamesPlayed != 1 {
+			t.Errorf("expected the duplicate's games to move too, got %+v", got)
+		}
+		if store.League.Find("chris") != nil {
+			t.Error("expected the duplicate to be gone")
+		}
+	})
+
+	t.Run("merge a missing player", func(t *testing.T) {
+		_, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPost, "/players/Chris/merge", `{"From": "Nobody"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("merge a player into themselves", func(t *testing.T) {
+		_, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodPost, "/players/Chris/merge", `{"From": "Chris"}`))
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("DELETE a player", func(t *testing.T) {
+		_, server := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodDelete, "/players/chris", ""))
+		poker.AssertResponseStatus(t, response.Code, http.StatusNoContent)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGetScoreRequest("chris"))
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodDelete, "/players/chris", ""))
+		poker.AssertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+	cases := []struct {
+		method, path, allow string
+	}{
+		{http.MethodPost, "/league", "GET, HEAD"},
+		{http.MethodDelete, "/players", "GET, HEAD"},
+		{http.MethodOptions, "/players/Chris", "DELETE, GET, HEAD, PATCH, POST, PUT"},
+		{http.MethodGet, "/players/Chris/merge", "POST"},
+		{http.MethodHead, "/players/Chris/merge", "POST"},
+		{http.MethodPut, "/games", "GET, HEAD, POST"},
+		{http.MethodDelete, "/seasons/2026-10", "GET, HEAD, POST"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s %s", c.method, c.path), func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(c.method, c.path, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusMethodNotAllowed)
+			if got := response.Header().Get("Allow"); got != c.allow {
+				t.Errorf("got Allow %q want %q", got, c.allow)
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	server := mustCreatePlayerServer(t, &poker.StubPlayerStore{League: poker.League{{"Chris", 1}}}, dummyGame)
+
+	for _, path := range []string{"/league", "/players", "/players/Chris", "/games"} {
+		t.Run(path, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newPlayersRequest(http.MethodHead, path, ""))
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		})
+	}
+}
+
+func newPlayersRequest(method, path, body string) *http.Request {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	return req
+}
+
+func assertScoreInLeague(t testing.TB, league poker.League, name string, wins int) {
+	t.Helper()
+
+	player := league.Find(name)
+	if player == nil {
+		t.Fatalf("expected %s in league %v", name, league)
+	}
+
+	if player.Wins != wins {
+		t.Errorf("got %d wins for %s want %d", player.Wins, name, wins)
+	}
+}
@@ -6,24 +6,27 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
 var dummyGame = &GameSpy{}
 
 func TestGETPlayers(t *testing.T) {
 	store := poker.StubPlayerStore{
-		map[string]int{
-			"Pepper": 20,
-			"Floyd":  10,
+		League: poker.League{
+			{"Pepper", 20},
+			{"Floyd", 10},
+		},
+		Games: []poker.GameRecord{
+			{ID: "a", Participants: []string{"Pepper", "Floyd"}, Winner: "Pepper"},
 		},
-		nil,
-		nil,
 	}
 	server := mustCreatePlayerServer(t, &store, dummyGame)
 
@@ -34,7 +37,11 @@ func TestGETPlayers(t *testing.T) {
 		server.ServeHTTP(response, request)
 
 		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
-		poker.AssertResponseBody(t, response.Body.String(), "20")
+		assertContentType(t, response, "application/json")
+		got := getPlayerFromResponse(t, response.Body)
+		if got.Wins != 20 || got.GamesPlayed != 1 || got.WinRate != 1 {
+			t.Errorf("got %+v", got)
+		}
 	})
 
 	t.Run("return Floyd's score", func(t *testing.T) {
@@ -44,7 +51,20 @@ func TestGETPlayers(t *testing.T) {
 		server.ServeHTTP(response, request)
 
 		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
-		poker.AssertResponseBody(t, response.Body.String(), "10")
+		assertPlayerWins(t, getPlayerFromResponse(t, response.Body), "Floyd", 10)
+	})
+
+	t.Run("return a player without wins", func(t *testing.T) {
+		store.League = append(store.League, poker.Player{"Ruth", 0})
+		defer func() { store.League = store.League[:2] }()
+
+		request := newGetScoreRequest("Ruth")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		assertPlayerWins(t, getPlayerFromResponse(t, response.Body), "Ruth", 0)
 	})
 
 	t.Run("returns 404 on missing players", func(t *testing.T) {
@@ -59,9 +79,7 @@ func TestGETPlayers(t *testing.T) {
 
 func TestStoreWins(t *testing.T) {
 	store := poker.StubPlayerStore{
-		map[string]int{},
-		nil,
-		nil,
+		Scores: map[string]int{},
 	}
 
 	server := mustCreatePlayerServer(t, &store, dummyGame)
@@ -88,6 +106,17 @@ func TestStoreWins(t *testing.T) {
 			t.Errorf("did not store correct winner got %q want %q", got_winner, player_name)
 		}
 	})
+
+	t.Run("it keeps a timestamped game for every posted win", func(t *testing.T) {
+		store := poker.StubPlayerStore{}
+		server := mustCreatePlayerServer(t, &store, dummyGame)
+
+		server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest("Cleo"))
+
+		if len(store.Games) != 1 || store.Games[0].Winner != "Cleo" || store.Games[0].FinishedAt.IsZero() {
+			t.Errorf("expected a game won by Cleo, got %+v", store.Games)
+		}
+	})
 }
 
 func TestLeague(t *testing.T) {
@@ -98,7 +127,7 @@ func TestLeague(t *testing.T) {
 			{"Tiest", 14},
 		}
 
-		store := poker.StubPlayerStore{nil, nil, wantedLeague}
+		store := poker.StubPlayerStore{League: wantedLeague}
 		server := mustCreatePlayerServer(t, &store, dummyGame)
 
 		request := newLeagueRequest()
@@ -111,6 +140,273 @@ func TestLeague(t *testing.T) {
 		poker.AssertLeague(t, got, wantedLeague)
 		assertContentType(t, response, "application/json")
 	})
+
+	t.Run("it sorts the league by win rate when asked", func(t *testing.T) {
+		store := poker.StubPlayerStore{
+			League: poker.League{{"Cleo", 3}, {"Chris", 1}},
+			Games: []poker.GameRecord{
+				{Participants: []string{"Cleo", "Chris"}, Winner: "Chris"},
+				{Participants: []string{"Cleo", "Chris"}, Winner: "Cleo"},
+				{Participants: []string{"Cleo"}, Winner: "Cleo"},
+				{Participants: []string{"Cleo", "Chris"}, Winner: "Cleo"},
+				{Participants: []string{"Cleo", "Chris", "Ruth"}, Winner: "Ruth"},
+			},
+		}
+		server := mustCreatePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=win_rate", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		got := getLeagueFromResponse(t, response.Body)
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, got, poker.League{{"Ruth", 0}, {"Cleo", 3}, {"Chris", 1}})
+	})
+
+	t.Run("it sorts the league by rating when asked", func(t *testing.T) {
+		store := poker.StubPlayerStore{
+			League: poker.League{{"Cleo", 2}, {"Chris", 1}},
+			Games: []poker.GameRecord{
+				{Participants: []string{"Cleo", "Chris"}, Winner: "Cleo"},
+				{Participants: []string{"Cleo", "Pepper"}, Winner: "Cleo"},
+				{Participants: []string{"Cleo", "Chris", "Ruth", "Pepper"}, Winner: "Chris"},
+			},
+		}
+		server := mustCreatePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=rating", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got []poker.PlayerStanding
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into standings, %v", err)
+		}
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		for i := 1; i < len(got); i++ {
+			if got[i-1].Rating < got[i].Rating {
+				t.Errorf("standings not sorted by rating %+v", got)
+			}
+		}
+	})
+
+	t.Run("it rejects unknown sort orders", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=height", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestRatingsCache(t *testing.T) {
+	engine := &countingEngine{RatingEngine: poker.Elo{K: 32, StartingRating: 1500}}
+	store := poker.NewNotifyingStore(&poker.StubPlayerStore{
+		League: poker.League{{"Cleo", 1}, {"Chris", 0}},
+		Games:  []poker.GameRecord{{Participants: []string{"Cleo", "Chris"}, Winner: "Cleo"}},
+	})
+	server := mustCreatePlayerServer(t, store, dummyGame)
+	server.UseRatingEngine(engine)
+
+	rating := func(t *testing.T, name string) float64 {
+		t.Helper()
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetScoreRequest(name))
+		return getPlayerFromResponse(t, response.Body).Rating
+	}
+
+	t.Run("games are only rated again after a write", func(t *testing.T) {
+		before := rating(t, "Chris")
+		server.ServeHTTP(httptest.NewRecorder(), newLeagueRequest())
+		rating(t, "Cleo")
+
+		if engine.rated != 1 {
+			t.Errorf("rated %d games want the one game rated once", engine.rated)
+		}
+
+		poker.AssertNoError(t, store.RecordGame(poker.GameRecord{Participants: []string{"Cleo", "Chris"}, Winner: "Chris"}))
+
+		if after := rating(t, "Chris"); after <= before {
+			t.Errorf("got rating %v after Chris won want more than %v", after, before)
+		}
+	})
+}
+
+// countingEngine counts the games it rates.
+type countingEngine struct {
+	poker.RatingEngine
+	rated int
+}
+
+func (e *countingEngine) Rate(ratings poker.Ratings, game poker.GameRecord) {
+	e.rated++
+	e.RatingEngine.Rate(ratings, game)
+}
+
+func TestSeasonLeagues(t *testing.T) {
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 21, 0, 0, 0, time.UTC)
+	}
+
+	newStore := func() *poker.StubPlayerStore {
+		return &poker.StubPlayerStore{
+			League: poker.League{{"Cleo", 3}, {"Chris", 1}},
+			Games: []poker.GameRecord{
+				{ID: "a", FinishedAt: at(9, 30), Winner: "Chris"},
+				{ID: "b", FinishedAt: at(10, 1), Winner: "Cleo"},
+				{ID: "c", FinishedAt: at(10, 2), Winner: "Cleo"},
+				{ID: "d", FinishedAt: at(11, 1), Winner: "Cleo"},
+			},
+		}
+	}
+
+	cases := []struct {
+		query string
+		want  poker.League
+	}{
+		{"season=2026-10", poker.League{{"Cleo", 2}}},
+		{"season=2026-09", poker.League{{"Chris", 1}}},
+		{"from=2026-10-02", poker.League{{"Cleo", 2}}},
+		{"from=2026-09-01&to=2026-10-02", poker.League{{"Chris", 1}, {"Cleo", 1}}},
+		{"to=2026-10-01T00:00:00Z", poker.League{{"Chris", 1}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			server := mustCreatePlayerServer(t, newStore(), dummyGame)
+
+			request, _ := http.NewRequest(http.MethodGet, "/league?"+c.query, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+			poker.AssertLeague(t, getLeagueFromResponse(t, response.Body), c.want)
+		})
+	}
+
+	for _, query := range []string{"season=october", "from=yesterday"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			server := mustCreatePlayerServer(t, newStore(), dummyGame)
+
+			request, _ := http.NewRequest(http.MethodGet, "/league?"+query, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("closing a season", func(t *testing.T) {
+		store := newStore()
+		server := mustCreatePlayerServer(t, store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/seasons/2026-09", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusCreated)
+		if len(store.Seasons) != 1 {
+			t.Fatalf("expected a season to be archived, got %+v", store.Seasons)
+		}
+		poker.AssertLeague(t, store.Seasons[0].League, poker.League{{"Chris", 1}})
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		poker.AssertResponseStatus(t, response.Code, http.StatusConflict)
+
+		request, _ = http.NewRequest(http.MethodGet, "/seasons", nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var seasons []poker.Season
+		if err := json.NewDecoder(response.Body).Decode(&seasons); err != nil {
+			t.Fatalf("Unable to parse response from server into seasons, %v", err)
+		}
+		if len(seasons) != 1 || seasons[0].ID != "2026-09" {
+			t.Errorf("got seasons %+v", seasons)
+		}
+	})
+
+	t.Run("closing a season that isn't over", func(t *testing.T) {
+		store := newStore()
+		server := mustCreatePlayerServer(t, store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/seasons/"+poker.SeasonOf(time.Now()).ID, nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusConflict)
+		if len(store.Seasons) != 0 {
+			t.Errorf("archived a season still being played, got %+v", store.Seasons)
+		}
+	})
+
+	t.Run("closing a badly named season", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, newStore(), dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/seasons/autumn", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestGames(t *testing.T) {
+	t.Run("it returns the recorded games as JSON", func(t *testing.T) {
+		wantedGames := []poker.GameRecord{
+			{ID: "b", NumberOfPlayers: 3, Participants: []string{"Chris", "Cleo", "Ruth"}, Winner: "Ruth"},
+			{ID: "a", NumberOfPlayers: 5, Winner: "Chris", BlindLevel: 2, Blind: 200},
+		}
+
+		store := poker.StubPlayerStore{Games: wantedGames}
+		server := mustCreatePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/games", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got []poker.GameRecord
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into games, %v", err)
+		}
+
+		poker.AssertResponseStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, "application/json")
+		if !reflect.DeepEqual(got, wantedGames) {
+			t.Errorf("got %+v want %+v", got, wantedGames)
+		}
+	})
+
+	t.Run("posted wins aren't listed as games", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		server := mustCreatePlayerServer(t, store, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newPostWinRequest("Pepper"))
+		poker.AssertResponseStatus(t, response.Code, http.StatusAccepted)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newPlayersRequest(http.MethodGet, "/games", ""))
+
+		var got []poker.GameRecord
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("Unable to parse response from server into games, %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("got games %+v want none", got)
+		}
+	})
 }
 
 func TestGame(t *testing.T) {
@@ -125,36 +421,213 @@ func TestGame(t *testing.T) {
 	})
 
 	t.Run("start a game with 3 players, send some blind alerts down WS and declare Ruth the winner", func(t *testing.T) {
-		wantedBlindAlert := "Blind is 100"
+		wantedBlindAlert := poker.BlindUpdate{Level: 1, SmallBlind: 100, BigBlind: 200}
 		winner := "Ruth"
 
-		game := &GameSpy{BlindAlert: []byte(wantedBlindAlert)}
+		game := &GameSpy{BlindAlert: &wantedBlindAlert}
 		dummyStore := &poker.StubPlayerStore{}
 		server := httptest.NewServer(mustCreatePlayerServer(t, dummyStore, game))
 		defer server.Close()
 
-		wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
-		ws := mustDialWS(t, wsURL)
+		ws, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
 		defer ws.Close()
 
-		writeWSMessage(t, ws, "3")
-		writeWSMessage(t, ws, winner)
+		sendWS(t, ws, poker.WSMessage{Type: poker.StartGameMessage, Players: 3})
+		assertWSMessage(t, ws, poker.WSMessage{Type: poker.BlindUpdateMessage, Blind: &wantedBlindAlert})
+		started := readWS(t, ws)
+		if started.Type != poker.GameStartedMessage || started.GameID == "" {
+			t.Errorf("expected the game to start with an id, got %+v", started)
+		}
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: winner})
+		assertWSMessage(t, ws, poker.WSMessage{Type: poker.GameOverMessage, GameID: started.GameID, Winner: winner})
 
 		assertGameStartedWith(t, game, 3)
 		assertFinishCalledWith(t, game, winner)
+	})
 
-		within(t, 10*time.Millisecond, func() {
-			assertWebsocketGotMsg(t, ws, wantedBlindAlert)
-		})
+	t.Run("control the blinds over WS", func(t *testing.T) {
+		game := &GameSpy{}
+		server := httptest.NewServer(mustCreatePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.StartGameMessage, Participants: []string{"Chris", "Ruth"}})
+		readWS(t, ws)
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.ControlMessage, Command: poker.PauseCommand})
+		assertWSMessage(t, ws, poker.WSMessage{Type: poker.ControlAppliedMessage, Command: poker.PauseCommand})
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.ControlMessage, Command: poker.NextCommand})
+		assertWSMessage(t, ws, poker.WSMessage{Type: poker.ControlAppliedMessage, Command: poker.NextCommand})
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.DeclareWinnerMessage, Winner: "Ruth"})
+		readWS(t, ws)
+		assertFinishCalledWith(t, game, "Ruth")
+
+		game.mu.Lock()
+		defer game.mu.Unlock()
+		if want := []string{poker.PauseCommand, poker.NextCommand}; !reflect.DeepEqual(game.Controls, want) {
+			t.Errorf("got controls %v want %v", game.Controls, want)
+		}
 	})
 
+	t.Run("a dropped connection abandons the game when the players don't come back", func(t *testing.T) {
+		game := &GameSpy{}
+		games := poker.NewGameManager(func() poker.Game { return game })
+		tables := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+		games.UseClock(tables)
+		server := httptest.NewServer(mustCreateTablesServer(t, &poker.StubPlayerStore{}, games))
+		defer server.Close()
+
+		ws, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		sendWS(t, ws, poker.WSMessage{Type: poker.StartGameMessage, Players: 3})
+		started := readWS(t, ws)
+		ws.Close()
+
+		tables.BlockUntil(1)
+		tables.Advance(poker.DefaultReconnectGrace)
+
+		game.mu.Lock()
+		ctx := game.StartedContext
+		game.mu.Unlock()
+
+		if ctx.Err() == nil {
+			t.Error("expected the game's context to be cancelled")
+		}
+
+		if _, open := games.Table(started.GameID); open {
+			t.Error("expected the abandoned table to be closed")
+		}
+
+		game.mu.Lock()
+		defer game.mu.Unlock()
+		if game.FinishCalled {
+			t.Errorf("expected an abandoned game not to finish, but it finished with %q", game.FinishedWith)
+		}
+	})
+
+	t.Run("GET /game offers the blind presets", func(t *testing.T) {
+		server := mustCreatePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest())
+
+		for _, preset := range poker.BlindPresets() {
+			if !strings.Contains(response.Body.String(), fmt.Sprintf("<option value=%q>", preset)) {
+				t.Errorf("expected an option for %s in the game page", preset)
+			}
+		}
+	})
+
+	t.Run("start a game with a blind preset", func(t *testing.T) {
+		game := &GameSpy{}
+		server := httptest.NewServer(mustCreatePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.StartGameMessage, Players: 3, Blinds: poker.TurboBlinds})
+		readWS(t, ws)
+
+		game.mu.Lock()
+		defer game.mu.Unlock()
+		if game.StartedBlinds == nil || game.StartedBlinds.Name != poker.TurboBlinds {
+			t.Errorf("expected game started with %s blinds, got %+v", poker.TurboBlinds, game.StartedBlinds)
+		}
+	})
+
+	t.Run("invalid messages get an error reply", func(t *testing.T) {
+		game := &GameSpy{}
+		server := httptest.NewServer(mustCreatePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws, _ := mustSitWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "3")
+		assertWSError(t, ws)
+
+		for _, msg := range []poker.WSMessage{
+			{Version: 2, Type: poker.StartGameMessage, Players: 3},
+			{Type: poker.DeclareWinnerMessage, Winner: "Ruth"},
+			{Type: poker.ControlMessage, Command: poker.PauseCommand},
+			{Type: poker.StartGameMessage, Players: 1},
+			{Type: poker.StartGameMessage, Participants: []string{"Chris", " "}},
+			{Type: poker.StartGameMessage, Players: 3, Participants: []string{"Chris", "Ruth"}},
+			{Type: poker.StartGameMessage, Players: 3, Blinds: "glacial"},
+			{Type: "deal"},
+		} {
+			sendWS(t, ws, msg)
+			assertWSError(t, ws)
+		}
+
+		sendWS(t, ws, poker.WSMessage{Type: poker.StartGameMessage, Players: 3})
+		if got := readWS(t, ws); got.Type != poker.GameStartedMessage {
+			t.Fatalf("expected the game to start, got %+v", got)
+		}
+
+		for _, msg := range []poker.WSMessage{
+			{Type: poker.StartGameMessage, Players: 3},
+			{Type: poker.ControlMessage, Command: "shuffle"},
+			{Type: poker.DeclareWinnerMessage, Winner: " "},
+		} {
+			sendWS(t, ws, msg)
+			assertWSError(t, ws)
+		}
+
+		game.mu.Lock()
+		defer game.mu.Unlock()
+		if game.StartedWith != 3 || game.FinishCalled {
+			t.Errorf("expected only the valid start to reach the game, got %+v", game)
+		}
+	})
+}
+
+// sendWS sends msg in the current version of the protocol, unless it has a
+// version of its own.
+func sendWS(t *testing.T, ws *websocket.Conn, msg poker.WSMessage) {
+	t.Helper()
+
+	if msg.Version == 0 {
+		msg.Version = poker.WSProtocolVersion
+	}
+
+	if err := ws.WriteJSON(msg); err != nil {
+		t.Fatalf("could not send %+v over ws connection %v", msg, err)
+	}
+}
+
+func readWS(t *testing.T, ws *websocket.Conn) poker.WSMessage {
+	t.Helper()
+
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+
+	var msg poker.WSMessage
+	if err := ws.ReadJSON(&msg); err != nil {
+		t.Fatalf("could not read a message from the ws connection %v", err)
+	}
+
+	return msg
+}
+
+func assertWSMessage(t *testing.T, ws *websocket.Conn, want poker.WSMessage) {
+	t.Helper()
+
+	want.Version = poker.WSProtocolVersion
+	if got := readWS(t, ws); !reflect.DeepEqual(got, want) {
+		t.Errorf("got message %+v, want %+v", got, want)
+	}
 }
 
-func assertWebsocketGotMsg(t *testing.T, ws *websocket.Conn, want string) {
-	_, gotMsg, _ := ws.ReadMessage()
+func assertWSError(t *testing.T, ws *websocket.Conn) {
+	t.Helper()
 
-	if string(gotMsg) != want {
-		t.Errorf("got %s, want %s", string(gotMsg), want)
+	if got := readWS(t, ws); got.Type != poker.ErrorMessage || got.Error == "" {
+		t.Errorf("expected an error reply, got %+v", got)
 	}
 }
 
@@ -177,6 +650,20 @@ func mustDialWS(t *testing.T, wsURL string) *websocket.Conn {
 	return ws
 }
 
+// mustSitWS joins a table as its players, returning the token they can
+// reconnect with.
+func mustSitWS(t *testing.T, wsURL string) (*websocket.Conn, string) {
+	t.Helper()
+
+	ws := mustDialWS(t, wsURL)
+	seated := readWS(t, ws)
+	if seated.Type != poker.SeatedMessage || seated.Token == "" {
+		t.Fatalf("expected to be seated with a token, got %+v", seated)
+	}
+
+	return ws, seated.Token
+}
+
 func newGameRequest() *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, "/game", nil)
 	return req
@@ -189,8 +676,15 @@ func assertContentType(t testing.TB, response *httptest.ResponseRecorder, want s
 	}
 }
 
+// mustCreatePlayerServer creates a server that plays every table with game.
 func mustCreatePlayerServer(t *testing.T, store poker.PlayerStore, game poker.Game) *poker.PlayerServer {
-	server, err := poker.NewPlayerServer(store, game)
+	t.Helper()
+
+	return mustCreateTablesServer(t, store, poker.NewGameManager(func() poker.Game { return game }))
+}
+
+func mustCreateTablesServer(t *testing.T, store poker.PlayerStore, games *poker.GameManager) *poker.PlayerServer {
+	server, err := poker.NewPlayerServer(store, games)
 	if err != nil {
 		t.Fatal("problem creating player server", err)
 	}
@@ -215,31 +709,32 @@ func newLeagueRequest() *http.Request {
 	return req
 }
 
-func getLeagueFromResponse(t testing.TB, body io.Reader) (league poker.League) {
+func getPlayerFromResponse(t testing.TB, body io.Reader) (player poker.PlayerStanding) {
 	t.Helper()
 
-	err := json.NewDecoder(body).Decode(&league)
+	err := json.NewDecoder(body).Decode(&player)
 	if err != nil {
-		t.Fatalf("Unable to parse response from server %q into slice of Player, '%v", body, err)
+		t.Fatalf("Unable to parse response from server %q into a player, '%v", body, err)
 	}
 
 	return
 }
 
-func within(t testing.TB, d time.Duration, assert func()) {
+func assertPlayerWins(t testing.TB, got poker.PlayerStanding, name string, wins int) {
 	t.Helper()
 
-	done := make(chan struct{}, 1)
+	if got.Name != name || got.Wins != wins {
+		t.Errorf("got %s with %d wins, want %s with %d", got.Name, got.Wins, name, wins)
+	}
+}
 
-	go func() {
-		assert()
-		done <- struct{}{}
-	}()
+func getLeagueFromResponse(t testing.TB, body io.Reader) (league poker.League) {
+	t.Helper()
 
-	select {
-	case <-time.After(d):
-		t.Error("timed out")
-	case <-done:
+	err := json.NewDecoder(body).Decode(&league)
+	if err != nil {
+		t.Fatalf("Unable to parse response from server %q into slice of Player, '%v", body, err)
 	}
 
+	return
 }
@@ -0,0 +1,422 @@
+package poker
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order, each exactly once. Only ever append to
+// this list, a database records how many of them it has already run.
+var migrations = []string{
+	`CREATE TABLE players (
+		name TEXT PRIMARY KEY,
+		wins INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX players_by_wins ON players (wins DESC)`,
+	`CREATE TABLE games (
+		id TEXT PRIMARY KEY,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP NOT NULL,
+		number_of_players INTEGER NOT NULL,
+		winner TEXT NOT NULL,
+		blind_level INTEGER NOT NULL,
+		blind INTEGER NOT NULL
+	)`,
+	`CREATE TABLE game_participants (
+		game_id TEXT NOT NULL REFERENCES games (id),
+		seat INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		PRIMARY KEY (game_id, seat)
+	)`,
+	`CREATE INDEX game_participants_by_name ON game_participants (name)`,
+	`CREATE INDEX games_by_finished_at ON games (finished_at)`,
+	`CREATE TABLE seasons (
+		id TEXT PRIMARY KEY,
+		starts_at TIMESTAMP NOT NULL,
+		ends_at TIMESTAMP NOT NULL,
+		closed_at TIMESTAMP NOT NULL,
+		league TEXT NOT NULL
+	)`,
+}
+
+type SQLitePlayerStore struct {
+	db *sql.DB
+}
+
+func (s *SQLitePlayerStore) GetPlayerScore(name string) int {
+	var wins int
+
+	err := s.db.QueryRow(`SELECT wins FROM players WHERE name = ?`, name).Scan(&wins)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("problem getting score for %s, %v\n", name, err)
+	}
+
+	return wins
+}
+
+func (s *SQLitePlayerStore) RecordWin(name string) error {
+	return s.RecordGame(postedWin(name, time.Now()))
+}
+
+func (s *SQLitePlayerStore) ImportLeague(league League) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("problem importing league, %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, player := range league {
+		_, err := tx.Exec(`
+			INSERT INTO players (name, wins) VALUES (?, ?)
+			ON CONFLICT (name) DO UPDATE SET wins = excluded.wins`, player.Name, player.Wins)
+		if err != nil {
+			return fmt.Errorf("problem importing %s, %v", player.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("problem importing league, %v", err)
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) GetLeague() League {
+	league := League{}
+
+	rows, err := s.db.Query(`SELECT name, wins FROM players ORDER BY wins DESC, rowid`)
+	if err != nil {
+		log.Printf("problem getting league, %v\n", err)
+		return league
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var player Player
+		if err := rows.Scan(&player.Name, &player.Wins); err != nil {
+			log.Printf("problem reading league, %v\n", err)
+			return league
+		}
+		league = append(league, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("problem reading league, %v\n", err)
+	}
+
+	return league
+}
+
+func (s *SQLitePlayerStore) RecordGame(game GameRecord) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("problem saving game %s, %v", game.ID, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO games (id, started_at, finished_at, number_of_players, winner, blind_level, blind)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		game.ID, game.StartedAt, game.FinishedAt, game.NumberOfPlayers, game.Winner, game.BlindLevel, game.Blind)
+	if err != nil {
+		return fmt.Errorf("problem saving game %s, %v", game.ID, err)
+	}
+
+	for seat, name := range game.Participants {
+		_, err := tx.Exec(`INSERT INTO game_participants (game_id, seat, name) VALUES (?, ?, ?)`, game.ID, seat, name)
+		if err != nil {
+			return fmt.Errorf("problem saving participants of game %s, %v", game.ID, err)
+		}
+	}
+
+	if game.Winner != "" {
+		_, err := tx.Exec(`
+			INSERT INTO players (name, wins) VALUES (?, 1)
+			ON CONFLICT (name) DO UPDATE SET wins = wins + 1`, game.Winner)
+		if err != nil {
+			return fmt.Errorf("problem saving win of game %s, %v", game.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("problem saving game %s, %v", game.ID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) GetGames() []GameRecord {
+	games := []GameRecord{}
+
+	rows, err := s.db.Query(`
+		SELECT id, started_at, finished_at, number_of_players, winner, blind_level, blind
+		FROM games ORDER BY finished_at DESC, rowid DESC`)
+	if err != nil {
+		log.Printf("problem getting games, %v\n", err)
+		return games
+	}
+	defer rows.Close()
+
+	byID := make(map[string]int)
+	for rows.Next() {
+		var game GameRecord
+		err := rows.Scan(&game.ID, &game.StartedAt, &game.FinishedAt, &game.NumberOfPlayers, &game.Winner, &game.BlindLevel, &game.Blind)
+		if err != nil {
+			log.Printf("problem reading games, %v\n", err)
+			return games
+		}
+		byID[game.ID] = len(games)
+		games = append(games, game)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("problem reading games, %v\n", err)
+		return games
+	}
+
+	participants, err := s.db.Query(`SELECT game_id, name FROM game_participants ORDER BY game_id, seat`)
+	if err != nil {
+		log.Printf("problem getting game participants, %v\n", err)
+		return games
+	}
+	defer participants.Close()
+
+	for participants.Next() {
+		var id, name string
+		if err := participants.Scan(&id, &name); err != nil {
+			log.Printf("problem reading game participants, %v\n", err)
+			return games
+		}
+		if i, ok := byID[id]; ok {
+			games[i].Participants = append(games[i].Participants, name)
+		}
+	}
+
+	if err := participants.Err(); err != nil {
+		log.Printf("problem reading game participants, %v\n", err)
+	}
+
+	return games
+}
+
+func (s *SQLitePlayerStore) ArchiveSeason(season Season) error {
+	league, err := json.Marshal(season.League)
+	if err != nil {
+		return fmt.Errorf("problem encoding league of season %s, %v", season.ID, err)
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO seasons (id, starts_at, ends_at, closed_at, league) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		season.ID, season.From, season.To, season.ClosedAt, string(league))
+	if err != nil {
+		return fmt.Errorf("problem saving season %s, %v", season.ID, err)
+	}
+
+	if added, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("problem saving season %s, %v", season.ID, err)
+	} else if added == 0 {
+		return fmt.Errorf("%w: %s", ErrSeasonClosed, season.ID)
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) GetSeasons() []Season {
+	seasons := []Season{}
+
+	rows, err := s.db.Query(`SELECT id, starts_at, ends_at, closed_at, league FROM seasons ORDER BY starts_at`)
+	if err != nil {
+		log.Printf("problem getting seasons, %v\n", err)
+		return seasons
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var season Season
+		var league string
+		if err := rows.Scan(&season.ID, &season.From, &season.To, &season.ClosedAt, &league); err != nil {
+			log.Printf("problem reading seasons, %v\n", err)
+			return seasons
+		}
+		if err := json.Unmarshal([]byte(league), &season.League); err != nil {
+			log.Printf("problem reading league of season %s, %v\n", season.ID, err)
+			return seasons
+		}
+		seasons = append(seasons, season)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("problem reading seasons, %v\n", err)
+	}
+
+	return seasons
+}
+
+func (s *SQLitePlayerStore) SetPlayerScore(name string, wins int) error {
+	_, err := s.db.Exec(`
+		INSERT INTO players (name, wins) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET wins = excluded.wins`, name, wins)
+	if err != nil {
+		return fmt.Errorf("problem saving score for %s, %v", name, err)
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) DeletePlayer(name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("problem deleting %s, %v", name, err)
+	}
+	defer tx.Rollback()
+
+	exists, err := sqlHasPlayer(tx, name)
+	if err != nil {
+		return fmt.Errorf("problem deleting %s, %v", name, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, name)
+	}
+
+	statements := []string{
+		`DELETE FROM players WHERE name = ?`,
+		`DELETE FROM game_participants WHERE name = ?`,
+		`UPDATE games SET winner = '' WHERE winner = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, name); err != nil {
+			return fmt.Errorf("problem deleting %s, %v", name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("problem deleting %s, %v", name, err)
+	}
+
+	return nil
+}
+
+func (s *SQLitePlayerStore) RenamePlayer(from, to string) error {
+	return s.movePlayer(from, to, false)
+}
+
+func (s *SQLitePlayerStore) MergePlayers(from, into string) error {
+	return s.movePlayer(from, into, true)
+}
+
+func (s *SQLitePlayerStore) movePlayer(from, to string, merge bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+	defer tx.Rollback()
+
+	fromExists, err := sqlHasPlayer(tx, from)
+	if err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+	toExists, err := sqlHasPlayer(tx, to)
+	if err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+
+	switch {
+	case !fromExists:
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, from)
+	case merge && !toExists:
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, to)
+	case !merge && toExists:
+		return fmt.Errorf("%w: %s", ErrPlayerExists, to)
+	}
+
+	statements := []string{
+		`INSERT INTO players (name, wins) SELECT ?, wins FROM players WHERE name = ?
+		 ON CONFLICT (name) DO UPDATE SET wins = wins + excluded.wins`,
+		`DELETE FROM players WHERE name = ?2`,
+		`UPDATE games SET winner = ?1 WHERE winner = ?2`,
+		`UPDATE game_participants SET name = ?1 WHERE name = ?2`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, to, from); err != nil {
+			return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("problem moving %s to %s, %v", from, to, err)
+	}
+
+	return nil
+}
+
+func sqlHasPlayer(tx *sql.Tx, name string) (bool, error) {
+	var exists bool
+
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM players WHERE name = ?1)
+			OR EXISTS (SELECT 1 FROM games WHERE winner = ?1)
+			OR EXISTS (SELECT 1 FROM game_participants WHERE name = ?1)`, name).Scan(&exists)
+
+	return exists, err
+}
+
+func NewSQLitePlayerStore(db *sql.DB) (*SQLitePlayerStore, error) {
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("problem migrating player db, %v", err)
+	}
+
+	return &SQLitePlayerStore{db}, nil
+}
+
+func SQLitePlayerStoreFromFile(path string) (*SQLitePlayerStore, func(), error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+
+	closeFunc := func() {
+		db.Close()
+	}
+
+	store, err := NewSQLitePlayerStore(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating sqlite store, %v", err)
+	}
+
+	return store, closeFunc, nil
+}
+
+func migrate(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			return fmt.Errorf("migration %d failed, %v", i+1, err)
+		}
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(migrations))); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
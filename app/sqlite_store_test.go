@@ -0,0 +1,71 @@
+package poker_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.db.sqlite")
+
+	store, closeFn, err := poker.SQLitePlayerStoreFromFile(path)
+	poker.AssertNoError(t, err)
+	defer closeFn()
+
+	recordWins(t, store, "Cleo", "Chris", "Chris", "Pepper", "Chris", "Cleo")
+
+	t.Run("league sorted by scores", func(t *testing.T) {
+		want := []poker.Player{
+			{"Chris", 3},
+			{"Cleo", 2},
+			{"Pepper", 1},
+		}
+
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("get player score", func(t *testing.T) {
+		assertScoreEquals(t, store.GetPlayerScore("Chris"), 3)
+		assertScoreEquals(t, store.GetPlayerScore("Nobody"), 0)
+	})
+
+	t.Run("wins survive reopening the database", func(t *testing.T) {
+		reopened, closeReopened, err := poker.SQLitePlayerStoreFromFile(path)
+		poker.AssertNoError(t, err)
+		defer closeReopened()
+
+		poker.AssertLeague(t, reopened.GetLeague(), store.GetLeague())
+	})
+
+	t.Run("empty database has an empty league", func(t *testing.T) {
+		empty, closeEmpty, err := poker.SQLitePlayerStoreFromFile(filepath.Join(t.TempDir(), "empty.sqlite"))
+		poker.AssertNoError(t, err)
+		defer closeEmpty()
+
+		poker.AssertLeague(t, empty.GetLeague(), poker.League{})
+	})
+}
+
+func TestSQLiteStoreMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.db.sqlite")
+
+	db, err := sql.Open("sqlite", path)
+	poker.AssertNoError(t, err)
+	defer db.Close()
+
+	_, err = poker.NewSQLitePlayerStore(db)
+	poker.AssertNoError(t, err)
+
+	_, err = poker.NewSQLitePlayerStore(db)
+	poker.AssertNoError(t, err)
+
+	var version int
+	poker.AssertNoError(t, db.QueryRow(`PRAGMA user_version`).Scan(&version))
+
+	if version == 0 {
+		t.Error("expected migrations to record the schema version")
+	}
+}
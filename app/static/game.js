@@ -0,0 +1,179 @@
+const startGame = document.getElementById('game-start')
+
+const declareWinner = document.getElementById('declare-winner')
+const submitWinnerButton = document.getElementById('winner-button')
+const winnerInput = document.getElementById('winner')
+
+const blindContainer = document.getElementById('blind-value')
+
+const gameContainer = document.getElementById('game')
+const gameEndContainer = document.getElementById('game-end')
+
+const blindControls = document.getElementById('blind-controls')
+
+declareWinner.hidden = true
+blindControls.hidden = true
+gameEndContainer.hidden = true
+
+const errorContainer = document.getElementById('error')
+
+const protocolVersion = 1
+
+const watchID = new URLSearchParams(document.location.search).get('watch')
+if (watchID && window['WebSocket']) {
+  watch(watchID)
+}
+
+startGame.addEventListener('click', event => {
+  const players = document.getElementById('player-count').value.trim()
+  const blinds = document.getElementById('blinds').value
+
+  const start = { type: 'start_game', blinds: blinds }
+  if (/^\d+$/.test(players)) {
+    start.players = Number(players)
+  } else {
+    start.participants = players.split(',').map(name => name.trim()).filter(name => name)
+  }
+
+  if (!window['WebSocket']) {
+    return
+  }
+
+  // Starting again, say after a bad list of players, stays at the table
+  // we're already sitting at rather than leaving it holding an empty seat.
+  if (seat) {
+    seat.then(restart => restart(start))
+    return
+  }
+
+  seat = fetch('/games', { method: 'POST' })
+    .then(response => response.json())
+    .then(table => play(table, start))
+    .catch(() => {
+      seat = null
+      errorContainer.innerText = "Couldn't open a table, try again"
+    })
+})
+
+let seat
+
+// play joins a table opened with POST /games, so each page gets a game of
+// its own. A dropped connection is retried with the token the table seated
+// us with, picking the game back up where it was. It returns a function
+// that starts the game at the table again.
+function play(table, start) {
+  let conn, token
+  let retries = 0
+  const send = message => conn.send(JSON.stringify(Object.assign({ version: protocolVersion }, message)))
+
+  submitWinnerButton.onclick = event => {
+    send({ type: 'declare_winner', winner: winnerInput.value })
+  }
+
+  blindControls.querySelectorAll('button').forEach(button => {
+    button.onclick = event => send({ type: 'control', command: button.dataset.command })
+  })
+
+  const sit = () => {
+    const path = '/games/' + table.ID + '/ws' + (token ? '?token=' + token : '')
+    conn = connect(path, message => {
+      if (message.type === 'seated') {
+        token = message.token
+        retries = 0
+        if (start) {
+          send(start)
+          start = null
+        }
+      }
+    }, () => {
+      if (token && !closing && gameEndContainer.hidden && retries < maxRetries) {
+        blindContainer.innerText = 'Reconnecting...'
+        setTimeout(sit, 1000 * 2 ** retries++)
+      }
+    })
+  }
+
+  sit()
+
+  return next => {
+    if (token && conn.readyState === WebSocket.OPEN) {
+      send(next)
+    } else {
+      start = next
+    }
+  }
+}
+
+const maxRetries = 6
+let closing = false
+
+// watch follows someone else's table, given as /game?watch={id}.
+function watch(id) {
+  startGame.hidden = true
+  connect('/games/' + id + '/watch')
+}
+
+// connect follows a table, passing each message on to onMessage and
+// calling onClose when the connection is gone.
+function connect(path, onMessage, onClose) {
+  const scheme = document.location.protocol === 'https:' ? 'wss://' : 'ws://'
+  const conn = new WebSocket(scheme + document.location.host + path)
+  const watching = path.endsWith('/watch')
+
+  conn.onclose = evt => {
+    blindContainer.innerText = 'Connection closed'
+    if (onClose) {
+      onClose()
+    }
+  }
+
+  conn.onmessage = evt => {
+    const message = JSON.parse(evt.data)
+    errorContainer.innerText = ''
+    if (onMessage) {
+      onMessage(message)
+    }
+
+    switch (message.type) {
+      case 'game_started':
+        startGame.hidden = true
+        declareWinner.hidden = watching
+        blindControls.hidden = watching
+        break
+      case 'blind_update':
+        blindContainer.innerText = describeBlind(message.blind)
+        break
+      case 'control_applied':
+        if (message.command === 'pause') {
+          blindContainer.innerText += ' (paused)'
+        }
+        break
+      case 'error':
+        errorContainer.innerText = message.error
+        break
+      case 'game_over':
+        gameEndContainer.hidden = false
+        gameContainer.hidden = true
+        break
+      case 'server_closing':
+        closing = true
+        errorContainer.innerText = 'The server is shutting down, so this game is over'
+        break
+    }
+  }
+
+  return conn
+}
+
+function describeBlind(blind) {
+  let text = blind.break
+    ? 'Break'
+    : 'Level ' + blind.level + ': blinds ' + blind.small_blind + '/' + blind.big_blind
+  if (blind.ante) {
+    text += ', ante ' + blind.ante
+  }
+  if (blind.next_change_at) {
+    text += ', until ' + new Date(blind.next_change_at).toLocaleTimeString()
+  }
+  return text
+}
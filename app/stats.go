@@ -0,0 +1,143 @@
+package poker
+
+import (
+	"sort"
+	"time"
+)
+
+// PlayerStats are worked out from recorded games. Wins that were posted
+// without a game only count towards Player.Wins, and games started with just a
+// number of players only towards the winner's stats.
+type PlayerStats struct {
+	GamesPlayed   int
+	GamesWon      int
+	WinRate       float64
+	CurrentStreak int
+	LongestStreak int
+	LastPlayed    *time.Time `json:",omitempty"`
+	Rating        float64    `json:",omitempty"`
+}
+
+type PlayerStanding struct {
+	Player
+	PlayerStats
+}
+
+const (
+	SortByWins    = "wins"
+	SortByWinRate = "win_rate"
+	SortByRating  = "rating"
+)
+
+// NewStandings adds stats to every player in the league, in league order.
+// Players who took part in games but never won are added at the end. With a
+// nil engine players are left unrated.
+func NewStandings(league League, games []GameRecord, engine RatingEngine) []PlayerStanding {
+	var ratings Ratings
+	if engine != nil {
+		ratings = RateGames(engine, games)
+	}
+
+	return standingsRated(league, games, engine, ratings)
+}
+
+// standingsRated is NewStandings with the ratings engine worked out from games
+// already to hand.
+func standingsRated(league League, games []GameRecord, engine RatingEngine, ratings Ratings) []PlayerStanding {
+	stats := statsFromGames(games)
+
+	if engine != nil {
+		for _, player := range league {
+			if _, ok := stats[player.Name]; !ok {
+				stats[player.Name] = PlayerStats{}
+			}
+		}
+		for name, s := range stats {
+			s.Rating = ratingOf(engine, ratings, name)
+			stats[name] = s
+		}
+	}
+
+	standings := make([]PlayerStanding, 0, len(league))
+	seen := make(map[string]bool, len(league))
+
+	for _, player := range league {
+		standings = append(standings, PlayerStanding{player, stats[player.Name]})
+		seen[player.Name] = true
+	}
+
+	var rest []string
+	for name := range stats {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	for _, name := range rest {
+		standings = append(standings, PlayerStanding{Player{name, 0}, stats[name]})
+	}
+
+	return standings
+}
+
+// SortStandings orders standings by wins, win rate or rating, keeping the
+// current order for ties.
+func SortStandings(standings []PlayerStanding, by string) {
+	sort.SliceStable(standings, func(i, j int) bool {
+		switch by {
+		case SortByWinRate:
+			return standings[i].WinRate > standings[j].WinRate
+		case SortByRating:
+			return standings[i].Rating > standings[j].Rating
+		}
+		return standings[i].Wins > standings[j].Wins
+	})
+}
+
+func statsFromGames(games []GameRecord) map[string]PlayerStats {
+	stats := make(map[string]PlayerStats)
+
+	for _, game := range inFinishingOrder(games) {
+		if game.isPostedWin() {
+			continue
+		}
+		finished := game.FinishedAt
+
+		for _, name := range playersIn(game) {
+			s := stats[name]
+			s.GamesPlayed++
+
+			if name == game.Winner {
+				s.GamesWon++
+				s.CurrentStreak++
+			} else {
+				s.CurrentStreak = 0
+			}
+
+			if s.CurrentStreak > s.LongestStreak {
+				s.LongestStreak = s.CurrentStreak
+			}
+
+			s.WinRate = float64(s.GamesWon) / float64(s.GamesPlayed)
+			s.LastPlayed = &finished
+			stats[name] = s
+		}
+	}
+
+	return stats
+}
+
+func playersIn(game GameRecord) []string {
+	var players []string
+	seen := make(map[string]bool)
+
+	for _, name := range append(game.Participants, game.Winner) {
+		if name != "" && !seen[name] {
+			players = append(players, name)
+			seen[name] = true
+		}
+	}
+
+	return players
+}
@@ -0,0 +1,85 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestNewStandings(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 22, 0, 0, 0, time.UTC)
+	}
+
+	games := []poker.GameRecord{
+		{ID: "4", FinishedAt: day(4), Participants: []string{"Chris", "Cleo", "Ruth"}, Winner: "Chris"},
+		{ID: "1", FinishedAt: day(1), Participants: []string{"Chris", "Cleo"}, Winner: "Cleo"},
+		{ID: "2", FinishedAt: day(2), Participants: []string{"Chris", "Cleo"}, Winner: "Cleo"},
+		{ID: "3", FinishedAt: day(3), Participants: []string{"Chris", "Cleo"}, Winner: "Chris"},
+		{ID: "5", FinishedAt: day(5), NumberOfPlayers: 6, Winner: "Pepper"},
+	}
+	league := poker.League{{"Cleo", 2}, {"Chris", 2}, {"Pepper", 1}}
+
+	got := poker.NewStandings(league, games, nil)
+
+	want := []poker.PlayerStanding{
+		{poker.Player{"Cleo", 2}, stats(4, 2, 0, 2, day(4))},
+		{poker.Player{"Chris", 2}, stats(4, 2, 2, 2, day(4))},
+		{poker.Player{"Pepper", 1}, stats(1, 1, 1, 1, day(5))},
+		{poker.Player{"Ruth", 0}, stats(1, 0, 0, 0, day(4))},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+
+	t.Run("sorted by win rate", func(t *testing.T) {
+		poker.SortStandings(got, poker.SortByWinRate)
+
+		var names []string
+		for _, standing := range got {
+			names = append(names, standing.Name)
+		}
+
+		wantNames := []string{"Pepper", "Cleo", "Chris", "Ruth"}
+		if !reflect.DeepEqual(names, wantNames) {
+			t.Errorf("got order %v want %v", names, wantNames)
+		}
+	})
+
+	t.Run("posted wins are left out of stats and ratings", func(t *testing.T) {
+		store := &poker.StubPlayerStore{Games: games}
+		poker.AssertNoError(t, store.RecordWin("Chris"))
+		poker.AssertNoError(t, store.RecordWin("Ruth"))
+
+		engine := poker.Elo{K: 32, StartingRating: 1500}
+		got := poker.NewStandings(league, store.Games, engine)
+		want := poker.NewStandings(league, games, engine)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("players without games have no stats", func(t *testing.T) {
+		got := poker.NewStandings(poker.League{{"Cleo", 3}}, nil, nil)
+		want := []poker.PlayerStanding{{Player: poker.Player{"Cleo", 3}}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
+
+func stats(played, won, current, longest int, lastPlayed time.Time) poker.PlayerStats {
+	return poker.PlayerStats{
+		GamesPlayed:   played,
+		GamesWon:      won,
+		WinRate:       float64(won) / float64(played),
+		CurrentStreak: current,
+		LongestStreak: longest,
+		LastPlayed:    &lastPlayed,
+	}
+}
@@ -0,0 +1,85 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	JSONStoreKind     = "json"
+	EventLogStoreKind = "eventlog"
+	SQLiteStoreKind   = "sqlite"
+)
+
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrPlayerExists   = errors.New("player already exists")
+)
+
+var defaultStorePaths = map[string]string{
+	JSONStoreKind:     "game.db.json",
+	EventLogStoreKind: "game.events.jsonl",
+	SQLiteStoreKind:   "game.db.sqlite",
+}
+
+// StorePath is path, or the default file for kind when path is empty.
+func StorePath(kind, path string) string {
+	if path == "" {
+		return defaultStorePaths[kind]
+	}
+
+	return path
+}
+
+// OpenPlayerStore opens the kind of store asked for at path, or at the
+// default file for that kind when path is empty.
+func OpenPlayerStore(kind, path string) (PlayerStore, func(), error) {
+	path = StorePath(kind, path)
+
+	switch kind {
+	case JSONStoreKind:
+		return FileSystemPlayerStoreFromFile(path)
+	case EventLogStoreKind:
+		return EventLogPlayerStoreFromFile(path)
+	case SQLiteStoreKind:
+		return SQLitePlayerStoreFromFile(path)
+	}
+
+	return nil, nil, fmt.Errorf("unknown store %q, expected %q, %q or %q", kind, JSONStoreKind, EventLogStoreKind, SQLiteStoreKind)
+}
+
+func hasPlayer(league League, games []GameRecord, name string) bool {
+	if league.Find(name) != nil {
+		return true
+	}
+
+	for _, game := range games {
+		for _, player := range playersIn(game) {
+			if player == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkRename returns the error a store should give for renaming from to to,
+// or for merging from into to when merge is set.
+func checkRename(league League, games []GameRecord, from, to string, merge bool) error {
+	if !hasPlayer(league, games, from) {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, from)
+	}
+
+	exists := hasPlayer(league, games, to)
+
+	if merge && !exists {
+		return fmt.Errorf("%w: %s", ErrPlayerNotFound, to)
+	}
+
+	if !merge && exists {
+		return fmt.Errorf("%w: %s", ErrPlayerExists, to)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package poker_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestOpenPlayerStore(t *testing.T) {
+	for _, kind := range []string{poker.JSONStoreKind, poker.EventLogStoreKind, poker.SQLiteStoreKind} {
+		t.Run(kind, func(t *testing.T) {
+			path := t.TempDir() + "/" + kind
+
+			store, closeFn, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+			defer closeFn()
+
+			poker.AssertNoError(t, store.RecordWin("Chris"))
+			assertScoreEquals(t, store.GetPlayerScore("Chris"), 1)
+		})
+	}
+
+	t.Run("unknown store", func(t *testing.T) {
+		_, _, err := poker.OpenPlayerStore("carrier-pigeon", "")
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestPlayerStoreGames(t *testing.T) {
+	for _, kind := range []string{poker.JSONStoreKind, poker.EventLogStoreKind, poker.SQLiteStoreKind} {
+		t.Run(kind, func(t *testing.T) {
+			path := t.TempDir() + "/" + kind
+
+			store, closeFn, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+
+			started := time.Date(2026, 10, 1, 20, 0, 0, 0, time.UTC)
+			first := poker.GameRecord{
+				ID:              "first",
+				StartedAt:       started,
+				FinishedAt:      started.Add(time.Hour),
+				NumberOfPlayers: 3,
+				Participants:    []string{"Chris", "Cleo", "Ruth"},
+				Winner:          "Cleo",
+				BlindLevel:      5,
+				Blind:           500,
+			}
+			second := poker.GameRecord{
+				ID:              "second",
+				StartedAt:       started.Add(2 * time.Hour),
+				FinishedAt:      started.Add(3 * time.Hour),
+				NumberOfPlayers: 4,
+				Winner:          "Chris",
+				BlindLevel:      4,
+				Blind:           400,
+			}
+
+			poker.AssertNoError(t, store.RecordGame(first))
+			poker.AssertNoError(t, store.RecordGame(second))
+			poker.AssertNoError(t, store.RecordWin("Chris"))
+
+			// the posted win is kept too, so it counts towards its season
+			assertPostedWin := func(t *testing.T, games []poker.GameRecord) {
+				t.Helper()
+
+				if posted := games[0]; posted.Winner != "Chris" || posted.FinishedAt.IsZero() || len(posted.Participants) != 0 {
+					t.Errorf("got %+v want Chris's posted win", posted)
+				}
+			}
+
+			want := []poker.GameRecord{second, first}
+			games := store.GetGames()
+			assertGames(t, games[1:], want)
+			assertPostedWin(t, games)
+			closeFn()
+
+			reopened, closeReopened, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+			defer closeReopened()
+
+			games = reopened.GetGames()
+			assertGames(t, games[1:], want)
+			assertPostedWin(t, games)
+
+			// recording a game counts its winner's win
+			assertScoreEquals(t, reopened.GetPlayerScore("Chris"), 2)
+			assertScoreEquals(t, reopened.GetPlayerScore("Cleo"), 1)
+		})
+	}
+}
+
+func TestPlayerStoreSeasons(t *testing.T) {
+	for _, kind := range []string{poker.JSONStoreKind, poker.EventLogStoreKind, poker.SQLiteStoreKind} {
+		t.Run(kind, func(t *testing.T) {
+			path := t.TempDir() + "/" + kind
+
+			store, closeFn, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+
+			season, _ := poker.ParseSeason("2026-09")
+			season.ClosedAt = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+			season.League = poker.League{{"Cleo", 4}, {"Chris", 2}}
+
+			poker.AssertNoError(t, store.ArchiveSeason(season))
+			assertEditErrorIs(t, store.ArchiveSeason(season), poker.ErrSeasonClosed)
+			closeFn()
+
+			reopened, closeReopened, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+			defer closeReopened()
+
+			got := reopened.GetSeasons()
+			if len(got) != 1 {
+				t.Fatalf("got %d seasons want 1", len(got))
+			}
+
+			if got[0].ID != season.ID || !got[0].From.Equal(season.From) || !got[0].To.Equal(season.To) || !got[0].ClosedAt.Equal(season.ClosedAt) {
+				t.Errorf("got season %+v want %+v", got[0], season)
+			}
+			poker.AssertLeague(t, got[0].League, season.League)
+		})
+	}
+}
+
+func TestPlayerStoreEdits(t *testing.T) {
+	for _, kind := range []string{poker.JSONStoreKind, poker.EventLogStoreKind, poker.SQLiteStoreKind} {
+		t.Run(kind, func(t *testing.T) {
+			path := t.TempDir() + "/" + kind
+
+			store, closeFn, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+
+			poker.AssertNoError(t, store.RecordGame(poker.GameRecord{ID: "a", Participants: []string{"chris", "Ruth"}, Winner: "chris"}))
+			poker.AssertNoError(t, store.RecordGame(poker.GameRecord{ID: "b", Participants: []string{"Cleo", "Pepper"}, Winner: "Pepper"}))
+			recordWins(t, store, "Chris", "Cleo", "Cleo")
+
+			poker.AssertNoError(t, store.SetPlayerScore("Cleo", 10))
+			poker.AssertNoError(t, store.SetPlayerScore("Floyd", 0))
+			poker.AssertNoError(t, store.MergePlayers("chris", "Chris"))
+			poker.AssertNoError(t, store.RenamePlayer("Ruth", "Ruthie"))
+			poker.AssertNoError(t, store.DeletePlayer("Pepper"))
+
+			assertEditErrorIs(t, store.RenamePlayer("Cleo", "Chris"), poker.ErrPlayerExists)
+			assertEditErrorIs(t, store.RenamePlayer("Nobody", "Somebody"), poker.ErrPlayerNotFound)
+			assertEditErrorIs(t, store.MergePlayers("Cleo", "Nobody"), poker.ErrPlayerNotFound)
+			assertEditErrorIs(t, store.DeletePlayer("Nobody"), poker.ErrPlayerNotFound)
+
+			assertEdited := func(t *testing.T, store poker.PlayerStore) {
+				t.Helper()
+
+				poker.AssertLeague(t, store.GetLeague(), poker.League{{"Cleo", 10}, {"Chris", 2}, {"Floyd", 0}})
+
+				// newest first, so the three posted wins come before the games
+				games := store.GetGames()
+				if len(games) != 5 {
+					t.Fatalf("got %d games want 5", len(games))
+				}
+				if !reflect.DeepEqual(games[4].Participants, []string{"Chris", "Ruthie"}) || games[4].Winner != "Chris" {
+					t.Errorf("game a not renamed, got %+v", games[4])
+				}
+				if !reflect.DeepEqual(games[3].Participants, []string{"Cleo"}) || games[3].Winner != "" {
+					t.Errorf("Pepper not removed from game b, got %+v", games[3])
+				}
+			}
+
+			assertEdited(t, store)
+			closeFn()
+
+			reopened, closeReopened, err := poker.OpenPlayerStore(kind, path)
+			poker.AssertNoError(t, err)
+			defer closeReopened()
+
+			assertEdited(t, reopened)
+		})
+	}
+}
+
+func assertEditErrorIs(t testing.TB, got, want error) {
+	t.Helper()
+
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v want %v", got, want)
+	}
+}
+
+func assertGames(t testing.TB, got, want []poker.GameRecord) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d games want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || !g.StartedAt.Equal(w.StartedAt) || !g.FinishedAt.Equal(w.FinishedAt) ||
+			g.NumberOfPlayers != w.NumberOfPlayers || !reflect.DeepEqual(g.Participants, w.Participants) ||
+			g.Winner != w.Winner || g.BlindLevel != w.BlindLevel || g.Blind != w.Blind {
+			t.Errorf("game %d, got %+v want %+v", i, g, w)
+		}
+	}
+}
@@ -1,15 +1,153 @@
 package poker
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
 )
 
+const journalSuffix = ".journal"
+
+// Tape rewrites the whole file on every Write. The new contents are first
+// written and synced to a journal next to the file, so a crash halfway
+// through rewriting the file can be recovered with RecoverTape.
 type Tape struct {
 	File *os.File
 }
 
 func (t *Tape) Write(p []byte) (n int, err error) {
-	t.File.Truncate(0)
-	t.File.Seek(0, 0)
-	return t.File.Write(p)
+	journal := journalPath(t.File)
+
+	if err := writeFileSync(journal, p); err != nil {
+		return 0, fmt.Errorf("problem writing journal %s, %v", journal, err)
+	}
+
+	n, err = overwriteFile(t.File, p)
+	if err != nil {
+		return n, fmt.Errorf("problem writing %s, %v", t.File.Name(), err)
+	}
+
+	if err := os.Remove(journal); err != nil {
+		return n, fmt.Errorf("problem removing journal %s, %v", journal, err)
+	}
+
+	return n, nil
+}
+
+// RecoverTape replays a journal left behind by an interrupted Write. A journal
+// that is not valid JSON was itself cut off, so it is discarded and the file
+// is left as it was.
+func RecoverTape(file *os.File) error {
+	journal := journalPath(file)
+
+	contents, err := os.ReadFile(journal)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem reading journal %s, %v", journal, err)
+	}
+
+	if json.Valid(contents) {
+		if _, err := overwriteFile(file, contents); err != nil {
+			return fmt.Errorf("problem restoring %s from journal, %v", file.Name(), err)
+		}
+	}
+
+	if err := os.Remove(journal); err != nil {
+		return fmt.Errorf("problem removing journal %s, %v", journal, err)
+	}
+
+	_, err = file.Seek(0, 0)
+	return err
+}
+
+func journalPath(file *os.File) string {
+	return file.Name() + journalSuffix
+}
+
+func overwriteFile(file *os.File, p []byte) (int, error) {
+	if err := file.Truncate(0); err != nil {
+		return 0, err
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		return 0, err
+	}
+
+	n, err := file.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	return n, file.Sync()
+}
+
+func writeFileSync(path string, p []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// replaceFile writes p to a temporary file next to path and renames it over
+// path, so readers see the old contents or the new but never part of them.
+func replaceFile(path string, p []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Write(p); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
 }
@@ -2,6 +2,7 @@ package poker_test
 
 import (
 	"io"
+	"os"
 	"testing"
 
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
@@ -25,3 +26,59 @@ func TestTape_Write(t *testing.T) {
 		t.Errorf("got %q want %q", got, want)
 	}
 }
+
+func TestTape_Journal(t *testing.T) {
+	t.Run("journal is removed after a successful write", func(t *testing.T) {
+		file, clean := createTempFile(t, "[]")
+		defer clean()
+
+		tape := &poker.Tape{file}
+		_, err := tape.Write([]byte(`[{"Name": "Chris", "Wins": 1}]`))
+		poker.AssertNoError(t, err)
+
+		if _, err := os.Stat(file.Name() + ".journal"); !os.IsNotExist(err) {
+			t.Errorf("expected journal to be removed, got %v", err)
+		}
+	})
+
+	t.Run("an interrupted write is recovered from the journal", func(t *testing.T) {
+		file, clean := createTempFile(t, `[{"Name": "Ch`)
+		defer clean()
+
+		writeJournal(t, file, `[{"Name": "Chris", "Wins": 2}]`)
+
+		store, err := poker.NewFileSystemStore(file)
+		poker.AssertNoError(t, err)
+
+		assertScoreEquals(t, store.GetPlayerScore("Chris"), 2)
+	})
+
+	t.Run("a torn journal is discarded", func(t *testing.T) {
+		file, clean := createTempFile(t, `[{"Name": "Chris", "Wins": 1}]`)
+		defer clean()
+
+		writeJournal(t, file, `[{"Name": "Chris", "Wi`)
+
+		store, err := poker.NewFileSystemStore(file)
+		poker.AssertNoError(t, err)
+
+		assertScoreEquals(t, store.GetPlayerScore("Chris"), 1)
+
+		if _, err := os.Stat(file.Name() + ".journal"); !os.IsNotExist(err) {
+			t.Errorf("expected journal to be removed, got %v", err)
+		}
+	})
+}
+
+func writeJournal(t testing.TB, file *os.File, contents string) {
+	t.Helper()
+
+	journal := file.Name() + ".journal"
+	if err := os.WriteFile(journal, []byte(contents), 0666); err != nil {
+		t.Fatalf("could not write journal %v", err)
+	}
+
+	t.Cleanup(func() {
+		os.Remove(journal)
+	})
+}
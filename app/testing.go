@@ -1,7 +1,7 @@
 package poker
 
 import (
-	"fmt"
+	"context"
 	"io"
 	"reflect"
 	"testing"
@@ -12,6 +12,8 @@ type StubPlayerStore struct {
 	Scores   map[string]int
 	WinCalls []string
 	League   League
+	Games    []GameRecord
+	Seasons  []Season
 }
 
 func (s *StubPlayerStore) GetLeague() League {
@@ -23,25 +25,78 @@ func (s *StubPlayerStore) GetPlayerScore(name string) int {
 	return score
 }
 
-func (s *StubPlayerStore) RecordWin(name string) {
-	s.WinCalls = append(s.WinCalls, name)
+func (s *StubPlayerStore) RecordWin(name string) error {
+	return s.RecordGame(postedWin(name, time.Now()))
 }
 
-type SpyBlindAlerter struct {
-	Alerts []ScheduledAlert
+func (s *StubPlayerStore) RecordGame(game GameRecord) error {
+	if game.Winner != "" {
+		s.WinCalls = append(s.WinCalls, game.Winner)
+	}
+	s.Games = append(s.Games, game)
+	return nil
+}
+
+func (s *StubPlayerStore) GetGames() []GameRecord {
+	return s.Games
+}
+
+func (s *StubPlayerStore) ArchiveSeason(season Season) error {
+	if _, ok := closedSeason(s.Seasons, season.ID); ok {
+		return ErrSeasonClosed
+	}
+
+	s.Seasons = append(s.Seasons, season)
+	return nil
+}
+
+func (s *StubPlayerStore) GetSeasons() []Season {
+	return s.Seasons
 }
 
-func (s *SpyBlindAlerter) ScheduleAlertAt(at time.Duration, amount int, to io.Writer) {
-	s.Alerts = append(s.Alerts, ScheduledAlert{at, amount})
+func (s *StubPlayerStore) SetPlayerScore(name string, wins int) error {
+	s.League = s.League.withScore(name, wins)
+	return nil
 }
 
-type ScheduledAlert struct {
-	At     time.Duration
-	Amount int
+func (s *StubPlayerStore) DeletePlayer(name string) error {
+	if !hasPlayer(s.League, s.Games, name) {
+		return ErrPlayerNotFound
+	}
+
+	s.League = s.League.without(name)
+	s.Games = renamedInGames(s.Games, name, "")
+	return nil
+}
+
+func (s *StubPlayerStore) RenamePlayer(from, to string) error {
+	return s.movePlayer(from, to, false)
+}
+
+func (s *StubPlayerStore) MergePlayers(from, into string) error {
+	return s.movePlayer(from, into, true)
+}
+
+func (s *StubPlayerStore) movePlayer(from, to string, merge bool) error {
+	if err := checkRename(s.League, s.Games, from, to, merge); err != nil {
+		return err
+	}
+
+	s.League = s.League.merged(from, to)
+	s.Games = renamedInGames(s.Games, from, to)
+	return nil
+}
+
+type SpyBlindAlerter struct {
+	Alerts   []ScheduledAlert
+	Updates  []BlindUpdate
+	Contexts []context.Context
 }
 
-func (s ScheduledAlert) String() string {
-	return fmt.Sprintf("%d chips at %v", s.Amount, s.At)
+func (s *SpyBlindAlerter) ScheduleAlertAt(ctx context.Context, at time.Duration, update BlindUpdate, to io.Writer) {
+	s.Alerts = append(s.Alerts, ScheduledAlert{at, update.SmallBlind})
+	s.Updates = append(s.Updates, update)
+	s.Contexts = append(s.Contexts, ctx)
 }
 
 func AssertPlayerWin(t testing.TB, store *StubPlayerStore, winner string) {
@@ -1,33 +1,278 @@
 package poker
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"sync"
 	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
+)
+
+var (
+	ErrNoGame     = errors.New("no game in progress")
+	ErrPaused     = errors.New("blinds are already paused")
+	ErrNotPaused  = errors.New("blinds aren't paused")
+	ErrFinalLevel = errors.New("already at the final level")
 )
 
 type TexasHoldem struct {
 	alerter BlindAlerter
 	store   PlayerStore
+	blinds  BlindStructure
+	clock   clock.Clock
+
+	mu      sync.Mutex
+	current GameRecord
+	running *blindClock
+	cancel  context.CancelFunc
+}
+
+// Start schedules the blind alerts of a game, which stop when it finishes, when
+// another game starts or when ctx is done.
+func (g *TexasHoldem) Start(ctx context.Context, setup GameSetup, alertsDestination io.Writer) error {
+	blinds := g.blinds
+	if setup.Blinds != nil {
+		blinds = *setup.Blinds
+	}
+
+	if err := blinds.Validate(); err != nil {
+		return fmt.Errorf("invalid blind structure %s, %v", blinds.Name, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	now := g.clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancel != nil {
+		g.cancel()
+	}
+	g.cancel = cancel
+
+	id := setup.ID
+	if id == "" {
+		id = newGameID()
+	}
+
+	g.current = GameRecord{
+		ID:              id,
+		StartedAt:       now.UTC(),
+		NumberOfPlayers: setup.NumberOfPlayers,
+		Participants:    setup.Participants,
+	}
+	g.running = &blindClock{
+		game:         ctx,
+		alerter:      g.alerter,
+		out:          alertsDestination,
+		schedule:     blinds.timeline(setup.NumberOfPlayers),
+		runningSince: now,
+	}
+	g.running.scheduleFrom(0)
+
+	return nil
 }
 
-func (g *TexasHoldem) Start(numberOfPlayers int, alertsDestination io.Writer) {
-	blindIncrement := time.Duration(5+numberOfPlayers) * time.Minute
-	blinds := []int{100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000}
-	blindTime := 0 * time.Second
+func (g *TexasHoldem) Finish(winner string) error {
+	now := g.clock.Now()
 
-	for _, blind := range blinds {
-		g.alerter.ScheduleAlertAt(blindTime, blind, alertsDestination)
-		blindTime = blindTime + blindIncrement
+	g.mu.Lock()
+	if g.cancel != nil {
+		g.cancel()
+		g.cancel = nil
+	}
+	record := g.current
+	record.FinishedAt = now.UTC()
+	record.Winner = winner
+	if g.running != nil {
+		record.BlindLevel, record.Blind = blindReached(g.running.schedule, g.running.played(now))
 	}
+	g.current = GameRecord{}
+	g.running = nil
+	g.mu.Unlock()
+
+	if record.ID == "" {
+		record.ID = newGameID()
+	}
+
+	return g.store.RecordGame(record)
+}
+
+// Pause stops the blind clock, holding back the remaining alerts until Resume.
+func (g *TexasHoldem) Pause() error {
+	return g.withBlindClock(func(running *blindClock, now time.Time) error {
+		if running.paused() {
+			return ErrPaused
+		}
+
+		running.elapsed = running.played(now)
+		running.runningSince = time.Time{}
+		running.cancelAlerts()
+		return nil
+	})
+}
+
+// Resume restarts a paused blind clock from where it stopped.
+func (g *TexasHoldem) Resume() error {
+	return g.withBlindClock(func(running *blindClock, now time.Time) error {
+		if !running.paused() {
+			return ErrNotPaused
+		}
+
+		running.runningSince = now
+		running.scheduleFrom(running.nextLevel(running.elapsed))
+		return nil
+	})
 }
 
-func (g *TexasHoldem) Finish(winner string) {
-	g.store.RecordWin(winner)
+// NextLevel jumps to the start of the next level, announcing it straight away.
+func (g *TexasHoldem) NextLevel() error {
+	return g.withBlindClock(func(running *blindClock, now time.Time) error {
+		next := running.nextLevel(running.played(now))
+		if next == len(running.schedule) {
+			return ErrFinalLevel
+		}
+
+		running.jumpTo(next, now)
+		return nil
+	})
 }
 
-func NewTexasHoldem(alerter BlindAlerter, store PlayerStore) *TexasHoldem {
+// PreviousLevel jumps back to the start of the level before the current one,
+// or restarts the first level.
+func (g *TexasHoldem) PreviousLevel() error {
+	return g.withBlindClock(func(running *blindClock, now time.Time) error {
+		previous := running.nextLevel(running.played(now)) - 2
+		if previous < 0 {
+			previous = 0
+		}
+
+		running.jumpTo(previous, now)
+		return nil
+	})
+}
+
+func (g *TexasHoldem) withBlindClock(f func(running *blindClock, now time.Time) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.running == nil {
+		return ErrNoGame
+	}
+
+	return f(g.running, g.clock.Now())
+}
+
+// NewTexasHoldem creates a game that plays blinds unless a game is started
+// with its own structure, timing them and announcing them with c.
+func NewTexasHoldem(c clock.Clock, store PlayerStore, blinds BlindStructure) *TexasHoldem {
 	return &TexasHoldem{
-		alerter: alerter,
+		alerter: NewAlerter(c),
 		store:   store,
+		blinds:  blinds,
+		clock:   c,
+	}
+}
+
+// UseBlindAlerter replaces the alerter made from the game's clock, so tests
+// can see what the game schedules.
+func (g *TexasHoldem) UseBlindAlerter(alerter BlindAlerter) {
+	g.alerter = alerter
+}
+
+// blindClock tracks how much of a game's schedule has been played. Time only
+// counts while it is running, and the pending alerts are rescheduled whenever
+// it's paused or moved.
+type blindClock struct {
+	game     context.Context
+	alerter  BlindAlerter
+	out      io.Writer
+	schedule []timedUpdate
+
+	elapsed      time.Duration
+	runningSince time.Time
+	cancel       context.CancelFunc
+}
+
+func (c *blindClock) paused() bool {
+	return c.runningSince.IsZero()
+}
+
+func (c *blindClock) played(now time.Time) time.Duration {
+	if c.paused() {
+		return c.elapsed
 	}
+
+	return c.elapsed + now.Sub(c.runningSince)
+}
+
+// nextLevel is the index of the first level that hasn't started after played.
+func (c *blindClock) nextLevel(played time.Duration) int {
+	for i, alert := range c.schedule {
+		if alert.At > played {
+			return i
+		}
+	}
+
+	return len(c.schedule)
+}
+
+func (c *blindClock) jumpTo(level int, now time.Time) {
+	c.elapsed = c.schedule[level].At
+	if !c.paused() {
+		c.runningSince = now
+	}
+
+	c.scheduleFrom(level)
+}
+
+// scheduleFrom replaces the pending alerts with those from level on, the first
+// of which is due now if it has already started. Nothing is due while paused.
+func (c *blindClock) scheduleFrom(level int) {
+	c.cancelAlerts()
+
+	ctx, cancel := context.WithCancel(c.game)
+	c.cancel = cancel
+
+	for _, alert := range c.schedule[level:] {
+		if c.paused() && alert.At > c.elapsed {
+			break
+		}
+
+		at := alert.At - c.elapsed
+		if at < 0 {
+			at = 0
+		}
+
+		update := alert.BlindUpdate
+		if c.paused() {
+			update.Lasts = 0
+		}
+		c.alerter.ScheduleAlertAt(ctx, at, update, c.out)
+	}
+}
+
+func (c *blindClock) cancelAlerts() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// blindReached returns the level and small blind of the last level in the
+// schedule that was due after elapsed time, or zeros without a schedule.
+// Breaks don't count as levels.
+func blindReached(schedule []timedUpdate, elapsed time.Duration) (level, amount int) {
+	for _, alert := range schedule {
+		if alert.At > elapsed {
+			break
+		}
+		if !alert.Break {
+			level, amount = alert.Level, alert.SmallBlind
+		}
+	}
+
+	return level, amount
 }
@@ -1,12 +1,16 @@
 package poker_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"reflect"
 	"testing"
 	"time"
 
 	poker "github.com/zmwilliam/learn-go-with-tests/app"
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
 func TestGame_Start(t *testing.T) {
@@ -15,8 +19,8 @@ func TestGame_Start(t *testing.T) {
 	t.Run("schedules alerts on game start for 5 players", func(t *testing.T) {
 		blindAlerter := &poker.SpyBlindAlerter{}
 
-		game := poker.NewTexasHoldem(blindAlerter, dummyPlayerStore)
-		game.Start(5, io.Discard)
+		game := newSpiedTexasHoldem(blindAlerter, dummyPlayerStore, standardBlinds)
+		game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5}, io.Discard)
 
 		cases := []poker.ScheduledAlert{
 			{0 * time.Second, 100},
@@ -38,8 +42,8 @@ func TestGame_Start(t *testing.T) {
 	t.Run("schedules alerts on game start for a 7 players", func(t *testing.T) {
 		blindAlerter := &poker.SpyBlindAlerter{}
 
-		game := poker.NewTexasHoldem(blindAlerter, dummyPlayerStore)
-		game.Start(7, io.Discard)
+		game := newSpiedTexasHoldem(blindAlerter, dummyPlayerStore, standardBlinds)
+		game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 7}, io.Discard)
 
 		cases := []poker.ScheduledAlert{
 			{0 * time.Second, 100},
@@ -50,6 +54,48 @@ func TestGame_Start(t *testing.T) {
 
 		checkSchedulingCases(t, cases, blindAlerter)
 	})
+
+	t.Run("schedules alerts from the blind structure the game is started with", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+		blinds := poker.BlindStructure{Name: "short", Levels: []poker.BlindLevel{
+			{SmallBlind: 50, BigBlind: 100, Duration: 15 * time.Minute},
+			{Duration: 5 * time.Minute, Break: true},
+			{SmallBlind: 100, BigBlind: 200, Ante: 25, Duration: 15 * time.Minute},
+		}}
+
+		game := newSpiedTexasHoldem(blindAlerter, dummyPlayerStore, standardBlinds)
+		poker.AssertNoError(t, game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5, Blinds: &blinds}, io.Discard))
+
+		cases := []poker.ScheduledAlert{
+			{0 * time.Second, 50},
+			{15 * time.Minute, 0},
+			{20 * time.Minute, 100},
+		}
+
+		checkSchedulingCases(t, cases, blindAlerter)
+
+		wantUpdates := []poker.BlindUpdate{
+			{Level: 1, SmallBlind: 50, BigBlind: 100, Lasts: 15 * time.Minute},
+			{Break: true, Lasts: 5 * time.Minute},
+			{Level: 2, SmallBlind: 100, BigBlind: 200, Ante: 25},
+		}
+		if !reflect.DeepEqual(blindAlerter.Updates, wantUpdates) {
+			t.Errorf("got updates %+v want %+v", blindAlerter.Updates, wantUpdates)
+		}
+	})
+
+	t.Run("won't start with an invalid blind structure", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+
+		game := newSpiedTexasHoldem(blindAlerter, dummyPlayerStore, poker.BlindStructure{})
+		if err := game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5}, io.Discard); err == nil {
+			t.Fatal("expected an error starting without blind levels")
+		}
+
+		if len(blindAlerter.Alerts) != 0 {
+			t.Errorf("expected no alerts but got %v", blindAlerter.Alerts)
+		}
+	})
 }
 
 func TestGame_Finish(t *testing.T) {
@@ -57,12 +103,242 @@ func TestGame_Finish(t *testing.T) {
 	store := &poker.StubPlayerStore{}
 	winner := "Ruth"
 
-	game := poker.NewTexasHoldem(dummyBlindAlerter, store)
+	game := newSpiedTexasHoldem(dummyBlindAlerter, store, standardBlinds)
 	game.Finish(winner)
 
 	poker.AssertPlayerWin(t, store, winner)
 }
 
+func TestGame_FinishRecordsGame(t *testing.T) {
+	dummyBlindAlerter := &poker.SpyBlindAlerter{}
+	store := &poker.StubPlayerStore{}
+	participants := []string{"Chris", "Ruth", "Cleo"}
+
+	game := newSpiedTexasHoldem(dummyBlindAlerter, store, standardBlinds)
+	poker.AssertNoError(t, game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 3, Participants: participants}, io.Discard))
+	poker.AssertNoError(t, game.Finish("Ruth"))
+
+	if len(store.Games) != 1 {
+		t.Fatalf("expected a game to be recorded but got %d", len(store.Games))
+	}
+
+	got := store.Games[0]
+
+	if got.ID == "" {
+		t.Error("expected game to have an id")
+	}
+
+	if got.Winner != "Ruth" || got.NumberOfPlayers != 3 || !reflect.DeepEqual(got.Participants, participants) {
+		t.Errorf("recorded wrong game %+v", got)
+	}
+
+	if got.StartedAt.IsZero() || got.FinishedAt.Before(got.StartedAt) {
+		t.Errorf("recorded wrong times, started %v finished %v", got.StartedAt, got.FinishedAt)
+	}
+
+	if got.BlindLevel != 1 || got.Blind != 100 {
+		t.Errorf("got blind level %d (%d) want 1 (100)", got.BlindLevel, got.Blind)
+	}
+}
+
+var standardBlinds, _ = poker.BlindPreset(poker.StandardBlinds)
+
+func TestGame_Cancel(t *testing.T) {
+	start := func(t *testing.T, ctx context.Context) (*poker.TexasHoldem, *poker.SpyBlindAlerter) {
+		t.Helper()
+
+		blindAlerter := &poker.SpyBlindAlerter{}
+		game := newSpiedTexasHoldem(blindAlerter, &poker.StubPlayerStore{}, standardBlinds)
+		poker.AssertNoError(t, game.Start(ctx, poker.GameSetup{NumberOfPlayers: 5}, io.Discard))
+
+		assertAlertsCancelled(t, blindAlerter, false)
+		return game, blindAlerter
+	}
+
+	t.Run("finishing cancels the alerts", func(t *testing.T) {
+		game, blindAlerter := start(t, context.Background())
+
+		poker.AssertNoError(t, game.Finish("Ruth"))
+
+		assertAlertsCancelled(t, blindAlerter, true)
+	})
+
+	t.Run("abandoning the game cancels the alerts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, blindAlerter := start(t, ctx)
+
+		cancel()
+
+		assertAlertsCancelled(t, blindAlerter, true)
+	})
+
+	t.Run("starting another game cancels the alerts", func(t *testing.T) {
+		game, blindAlerter := start(t, context.Background())
+		scheduled := len(blindAlerter.Alerts)
+
+		poker.AssertNoError(t, game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5}, io.Discard))
+
+		for i, ctx := range blindAlerter.Contexts {
+			if cancelled := ctx.Err() != nil; cancelled != (i < scheduled) {
+				t.Errorf("alert %d cancelled %v", i, cancelled)
+			}
+		}
+	})
+}
+
+func TestGame_BlindControls(t *testing.T) {
+	start := func(t *testing.T) (*poker.TexasHoldem, *poker.SpyBlindAlerter, *poker.StubPlayerStore) {
+		t.Helper()
+
+		blindAlerter := &poker.SpyBlindAlerter{}
+		store := &poker.StubPlayerStore{}
+		game := newSpiedTexasHoldem(blindAlerter, store, standardBlinds)
+		poker.AssertNoError(t, game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5}, io.Discard))
+
+		return game, blindAlerter, store
+	}
+
+	t.Run("next announces the next level and reschedules the rest", func(t *testing.T) {
+		game, blindAlerter, _ := start(t)
+		scheduled := len(blindAlerter.Alerts)
+
+		poker.AssertNoError(t, game.NextLevel())
+
+		assertAlertsCancelled(t, &poker.SpyBlindAlerter{Contexts: blindAlerter.Contexts[:scheduled]}, true)
+		assertScheduled(t, blindAlerter.Alerts[scheduled:], []poker.ScheduledAlert{
+			{0, 200}, {10 * time.Minute, 300}, {20 * time.Minute, 400}, {30 * time.Minute, 500},
+			{40 * time.Minute, 600}, {50 * time.Minute, 800}, {60 * time.Minute, 1000},
+			{70 * time.Minute, 2000}, {80 * time.Minute, 4000}, {90 * time.Minute, 8000},
+		})
+	})
+
+	t.Run("pause holds back alerts until resumed", func(t *testing.T) {
+		game, blindAlerter, _ := start(t)
+		poker.AssertNoError(t, game.NextLevel())
+
+		poker.AssertNoError(t, game.Pause())
+		assertAlertsCancelled(t, blindAlerter, true)
+
+		scheduled := len(blindAlerter.Alerts)
+		poker.AssertNoError(t, game.PreviousLevel())
+		assertScheduled(t, blindAlerter.Alerts[scheduled:], []poker.ScheduledAlert{{0, 100}})
+
+		scheduled = len(blindAlerter.Alerts)
+		poker.AssertNoError(t, game.Resume())
+		resumed := blindAlerter.Alerts[scheduled:]
+		if len(resumed) != 10 {
+			t.Fatalf("got %d alerts after resuming want 10", len(resumed))
+		}
+		assertScheduled(t, resumed[:2], []poker.ScheduledAlert{{10 * time.Minute, 200}, {20 * time.Minute, 300}})
+	})
+
+	t.Run("finishing records the level reached", func(t *testing.T) {
+		game, _, store := start(t)
+		poker.AssertNoError(t, game.NextLevel())
+		poker.AssertNoError(t, game.NextLevel())
+		poker.AssertNoError(t, game.Finish("Ruth"))
+
+		if got := store.Games[0]; got.BlindLevel != 3 || got.Blind != 300 {
+			t.Errorf("got blind level %d (%d) want 3 (300)", got.BlindLevel, got.Blind)
+		}
+	})
+
+	t.Run("controls that don't apply", func(t *testing.T) {
+		game, _, _ := start(t)
+
+		assertControlError(t, game.Resume(), poker.ErrNotPaused)
+		poker.AssertNoError(t, game.Pause())
+		assertControlError(t, game.Pause(), poker.ErrPaused)
+
+		for i := 0; i < 10; i++ {
+			poker.AssertNoError(t, game.NextLevel())
+		}
+		assertControlError(t, game.NextLevel(), poker.ErrFinalLevel)
+
+		poker.AssertNoError(t, game.Finish("Ruth"))
+		assertControlError(t, game.Pause(), poker.ErrNoGame)
+	})
+}
+
+func TestGame_PlaysTheBlindSchedule(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC))
+	out := &bytes.Buffer{}
+	store := &poker.StubPlayerStore{}
+
+	game := poker.NewTexasHoldem(fake, store, standardBlinds)
+	poker.AssertNoError(t, game.Start(context.Background(), poker.GameSetup{NumberOfPlayers: 5}, out))
+
+	fake.Advance(0)
+	assertMessagesSentToUser(t, out, "Blind is now 100\n")
+
+	fake.Advance(25 * time.Minute)
+	poker.AssertNoError(t, game.Pause())
+	fake.Advance(time.Hour)
+	assertMessagesSentToUser(t, out, "Blind is now 100\n", "Blind is now 200\n", "Blind is now 300\n")
+
+	out.Reset()
+	poker.AssertNoError(t, game.Resume())
+	fake.Advance(5 * time.Minute)
+	assertMessagesSentToUser(t, out, "Blind is now 400\n")
+
+	out.Reset()
+	fake.Advance(70 * time.Minute)
+	assertMessagesSentToUser(t, out,
+		"Blind is now 500\n", "Blind is now 600\n", "Blind is now 800\n", "Blind is now 1000\n",
+		"Blind is now 2000\n", "Blind is now 4000\n", "Blind is now 8000\n",
+	)
+
+	poker.AssertNoError(t, game.Finish("Ruth"))
+
+	got := store.Games[0]
+	if want := time.Date(2026, 10, 17, 22, 40, 0, 0, time.UTC); !got.FinishedAt.Equal(want) {
+		t.Errorf("got finished at %v want %v", got.FinishedAt, want)
+	}
+	if got.BlindLevel != 11 || got.Blind != 8000 {
+		t.Errorf("got blind level %d (%d) want 11 (8000)", got.BlindLevel, got.Blind)
+	}
+	if fake.Pending() != 0 {
+		t.Errorf("got %d alerts still pending after finishing", fake.Pending())
+	}
+}
+
+// newSpiedTexasHoldem creates a game whose blinds are scheduled on alerter.
+func newSpiedTexasHoldem(alerter poker.BlindAlerter, store poker.PlayerStore, blinds poker.BlindStructure) *poker.TexasHoldem {
+	game := poker.NewTexasHoldem(clock.Real{}, store, blinds)
+	game.UseBlindAlerter(alerter)
+	return game
+}
+
+func assertScheduled(t testing.TB, got, want []poker.ScheduledAlert) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got alerts %v want %v", got, want)
+	}
+}
+
+func assertControlError(t testing.TB, got, want error) {
+	t.Helper()
+
+	if got != want {
+		t.Errorf("got error %v want %v", got, want)
+	}
+}
+
+func assertAlertsCancelled(t testing.TB, blindAlerter *poker.SpyBlindAlerter, want bool) {
+	t.Helper()
+
+	if len(blindAlerter.Contexts) == 0 {
+		t.Fatal("expected alerts to be scheduled")
+	}
+
+	for i, ctx := range blindAlerter.Contexts {
+		if cancelled := ctx.Err() != nil; cancelled != want {
+			t.Errorf("alert %d cancelled %v want %v", i, cancelled, want)
+		}
+	}
+}
+
 func checkSchedulingCases(t *testing.T, cases []poker.ScheduledAlert, blindAlerter *poker.SpyBlindAlerter) {
 	t.Helper()
 
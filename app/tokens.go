@@ -0,0 +1,296 @@
+package poker
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrTokenExists   = errors.New("token already exists")
+)
+
+// Role is what a token lets its holder do. Each role can do everything the
+// ones before it can.
+type Role int
+
+const (
+	RoleNone Role = iota
+	// RoleViewer can only read.
+	RoleViewer
+	// RoleDealer can also play games and record wins.
+	RoleDealer
+	// RoleAdmin can also edit, merge and delete players and close seasons.
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{RoleViewer: "viewer", RoleDealer: "dealer", RoleAdmin: "admin"}
+
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if roleName == name {
+			return role, nil
+		}
+	}
+
+	return RoleNone, fmt.Errorf("unknown role %q, expected viewer, dealer or admin", name)
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "none"
+}
+
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Role) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseRole(string(text))
+	return err
+}
+
+// APIToken is a named token. Only a hash of the secret is kept, so a lost
+// token has to be revoked and created again.
+type APIToken struct {
+	Name      string
+	Role      Role
+	CreatedAt time.Time
+	Hash      string
+}
+
+// TokenStore finds tokens by the hash of their secret.
+type TokenStore interface {
+	Token(hash string) (APIToken, bool)
+}
+
+// HashToken is how a token's secret is kept and looked up.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// FileTokenStore keeps tokens in a JSON file, which it reads again whenever
+// it changes, so tokens made or revoked by the cli apply to a running server.
+type FileTokenStore struct {
+	path string
+
+	mu     sync.Mutex
+	tokens []APIToken
+	// info is the file the tokens were read from, nil while there's none.
+	info os.FileInfo
+}
+
+// TokenStoreFromFile opens the tokens at path. A missing file is an empty
+// store, written on the first new token.
+func TokenStoreFromFile(path string) (*FileTokenStore, error) {
+	store := &FileTokenStore{path: path}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *FileTokenStore) Token(hash string) (APIToken, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return APIToken{}, false
+	}
+
+	for _, token := range s.tokens {
+		if token.Hash == hash {
+			return token, true
+		}
+	}
+
+	return APIToken{}, false
+}
+
+// Create makes a token called name with role, returning its secret.
+func (s *FileTokenStore) Create(name string, role Role, now time.Time) (string, error) {
+	if name == "" {
+		return "", errors.New("a token needs a name")
+	}
+	if _, ok := roleNames[role]; !ok {
+		return "", fmt.Errorf("unknown role %v", role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return "", err
+	}
+
+	for _, token := range s.tokens {
+		if token.Name == name {
+			return "", fmt.Errorf("%w: %s", ErrTokenExists, name)
+		}
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("problem making token %v", err)
+	}
+	token := "pk_" + hex.EncodeToString(secret)
+
+	s.tokens = append(s.tokens, APIToken{Name: name, Role: role, CreatedAt: now.UTC(), Hash: HashToken(token)})
+	return token, s.save()
+}
+
+// Revoke deletes the token called name.
+func (s *FileTokenStore) Revoke(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	for i, token := range s.tokens {
+		if token.Name == name {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			return s.save()
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrTokenNotFound, name)
+}
+
+// List returns the tokens by name.
+func (s *FileTokenStore) List() ([]APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	tokens := append([]APIToken{}, s.tokens...)
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+
+	return tokens, nil
+}
+
+// reload reads the file again if it changed since it was last read. Saves
+// replace the file, so a new file counts as a change even when its size and
+// mod time happen to match the old one's.
+func (s *FileTokenStore) reload() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.tokens, s.info = nil, nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem opening tokens %s, %v", s.path, err)
+	}
+
+	if s.info != nil && os.SameFile(s.info, info) && info.ModTime().Equal(s.info.ModTime()) && info.Size() == s.info.Size() {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("problem opening tokens %s, %v", s.path, err)
+	}
+
+	tokens := []APIToken{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return fmt.Errorf("problem parsing tokens %s, %v", s.path, err)
+	}
+
+	s.tokens, s.info = tokens, info
+	return nil
+}
+
+func (s *FileTokenStore) save() error {
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("problem encoding tokens %v", err)
+	}
+
+	if err := replaceFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("problem writing tokens %s, %v", s.path, err)
+	}
+
+	if info, err := os.Stat(s.path); err == nil {
+		s.info = info
+	}
+
+	return nil
+}
+
+// TokenCommand runs the cli's token subcommand with args of
+//
+//	create NAME ROLE   print a new token for NAME
+//	list               show every token
+//	revoke NAME        delete NAME's token
+func TokenCommand(out io.Writer, tokens *FileTokenStore, args []string, now time.Time) error {
+	command := ""
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch {
+	case command == "create" && len(args) == 2:
+		role, err := ParseRole(args[1])
+		if err != nil {
+			return err
+		}
+
+		secret, err := tokens.Create(args[0], role, now)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Created %s token for %s, it won't be shown again:\n%s\n", role, args[0], secret)
+		return nil
+
+	case command == "list" && len(args) == 0:
+		list, err := tokens.List()
+		if err != nil {
+			return err
+		}
+
+		if len(list) == 0 {
+			fmt.Fprintln(out, "No tokens yet")
+			return nil
+		}
+
+		table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "NAME\tROLE\tCREATED")
+		for _, token := range list {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", token.Name, token.Role, token.CreatedAt.Format(time.RFC3339))
+		}
+		return table.Flush()
+
+	case command == "revoke" && len(args) == 1:
+		if err := tokens.Revoke(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Revoked %s's token\n", args[0])
+		return nil
+	}
+
+	return errors.New("usage: token create NAME ROLE | token list | token revoke NAME")
+}
@@ -0,0 +1,155 @@
+package poker_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/zmwilliam/learn-go-with-tests/app"
+)
+
+func TestTokenCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	tokens, err := poker.TokenStoreFromFile(path)
+	poker.AssertNoError(t, err)
+
+	now := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		out := &bytes.Buffer{}
+		err := poker.TokenCommand(out, tokens, args, now)
+		return out.String(), err
+	}
+
+	var secret string
+
+	t.Run("create prints the secret once", func(t *testing.T) {
+		out, err := run(t, "create", "Chris", "dealer")
+		poker.AssertNoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		secret = lines[len(lines)-1]
+		if !strings.HasPrefix(secret, "pk_") {
+			t.Fatalf("got output %q want a new token on its last line", out)
+		}
+	})
+
+	t.Run("the server sees new tokens without restarting", func(t *testing.T) {
+		server, err := poker.TokenStoreFromFile(path)
+		poker.AssertNoError(t, err)
+
+		_, err = run(t, "create", "Cleo", "viewer")
+		poker.AssertNoError(t, err)
+
+		token, ok := server.Token(poker.HashToken(secret))
+		if !ok || token.Name != "Chris" || token.Role != poker.RoleDealer {
+			t.Errorf("got token %+v, %v want Chris's dealer token", token, ok)
+		}
+
+		if _, ok := server.Token(poker.HashToken("pk_wrong")); ok {
+			t.Error("found a token that was never created")
+		}
+	})
+
+	t.Run("the file never holds the secret", func(t *testing.T) {
+		list, err := tokens.List()
+		poker.AssertNoError(t, err)
+
+		for _, token := range list {
+			if token.Hash == secret {
+				t.Errorf("token %s stored its secret", token.Name)
+			}
+		}
+	})
+
+	t.Run("the file is replaced whole and only its owner can read it", func(t *testing.T) {
+		info, err := os.Stat(path)
+		poker.AssertNoError(t, err)
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("got file mode %v want %v", mode, os.FileMode(0600))
+		}
+
+		files, err := os.ReadDir(filepath.Dir(path))
+		poker.AssertNoError(t, err)
+		if len(files) != 1 {
+			t.Errorf("got files %v want just the tokens", files)
+		}
+	})
+
+	t.Run("list shows every token", func(t *testing.T) {
+		out, err := run(t, "list")
+		poker.AssertNoError(t, err)
+
+		want := "NAME   ROLE    CREATED\n" +
+			"Chris  dealer  2024-03-01T20:00:00Z\n" +
+			"Cleo   viewer  2024-03-01T20:00:00Z\n"
+		if out != want {
+			t.Errorf("got\n%s\nwant\n%s", out, want)
+		}
+	})
+
+	t.Run("names are unique and roles must exist", func(t *testing.T) {
+		if _, err := run(t, "create", "Chris", "admin"); !errors.Is(err, poker.ErrTokenExists) {
+			t.Errorf("got error %v want %v", err, poker.ErrTokenExists)
+		}
+		if _, err := run(t, "create", "Pepper", "owner"); err == nil {
+			t.Error("created a token with an unknown role")
+		}
+	})
+
+	t.Run("revoke deletes the token", func(t *testing.T) {
+		out, err := run(t, "revoke", "Chris")
+		poker.AssertNoError(t, err)
+		if want := "Revoked Chris's token\n"; out != want {
+			t.Errorf("got %q want %q", out, want)
+		}
+
+		if _, ok := tokens.Token(poker.HashToken(secret)); ok {
+			t.Error("revoked token still works")
+		}
+
+		if _, err := run(t, "revoke", "Chris"); !errors.Is(err, poker.ErrTokenNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrTokenNotFound)
+		}
+	})
+
+	t.Run("a replaced file is read again even if it looks the same", func(t *testing.T) {
+		server, err := poker.TokenStoreFromFile(path)
+		poker.AssertNoError(t, err)
+
+		list, err := tokens.List()
+		poker.AssertNoError(t, err)
+		cleo := list[0]
+		if _, ok := server.Token(cleo.Hash); !ok {
+			t.Fatal("expected the server to know Cleo's token")
+		}
+
+		info, err := os.Stat(path)
+		poker.AssertNoError(t, err)
+		data, err := os.ReadFile(path)
+		poker.AssertNoError(t, err)
+
+		// Another token of the same length, written at the same mod time.
+		revoked := strings.Replace(string(data), cleo.Hash, poker.HashToken("pk_other"), 1)
+		replacement := path + ".new"
+		poker.AssertNoError(t, os.WriteFile(replacement, []byte(revoked), 0600))
+		poker.AssertNoError(t, os.Chtimes(replacement, info.ModTime(), info.ModTime()))
+		poker.AssertNoError(t, os.Rename(replacement, path))
+
+		if _, ok := server.Token(cleo.Hash); ok {
+			t.Error("a token revoked by replacing the file still works")
+		}
+	})
+
+	t.Run("anything else is a usage error", func(t *testing.T) {
+		if _, err := run(t, "rotate", "Cleo"); err == nil {
+			t.Error("expected a usage error")
+		}
+	})
+}
@@ -0,0 +1,170 @@
+// Package clock lets code that waits on time be driven by a fake clock in
+// tests.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a pending call or tick. C is nil for timers made by AfterFunc.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Sleep blocks until d has passed on c.
+func Sleep(c Clock, d time.Duration) {
+	<-c.NewTimer(d).C()
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (Real) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// Fake is a clock that only moves when advanced, firing the timers that fall
+// due on the way in order.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.add(d, fn, nil)
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.add(d, nil, make(chan time.Time, 1))
+}
+
+// Advance moves the clock on by d, firing timers as it passes them. Functions
+// run on the caller's goroutine, and can schedule timers that fire within d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	until := f.now.Add(d)
+
+	for len(f.timers) > 0 && !f.timers[0].at.After(until) {
+		timer := f.timers[0]
+		f.timers = f.timers[1:]
+		f.now = timer.at
+		f.changed.Broadcast()
+		f.mu.Unlock()
+
+		timer.fire()
+
+		f.mu.Lock()
+	}
+
+	f.now = until
+	f.mu.Unlock()
+}
+
+// BlockUntil waits until n timers are pending, for when another goroutine
+// has to start waiting before the clock is advanced.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.timers) != n {
+		f.changed.Wait()
+	}
+}
+
+// Pending is the number of timers that haven't fired or been stopped.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+func (f *Fake) add(d time.Duration, fn func(), c chan time.Time) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	timer := &fakeTimer{fake: f, at: f.now.Add(d), fn: fn, c: c}
+
+	// Stable so timers due at the same time fire in the order they were made.
+	i := sort.Search(len(f.timers), func(i int) bool { return f.timers[i].at.After(timer.at) })
+	f.timers = append(f.timers, nil)
+	copy(f.timers[i+1:], f.timers[i:])
+	f.timers[i] = timer
+
+	f.changed.Broadcast()
+	return timer
+}
+
+type fakeTimer struct {
+	fake *Fake
+	at   time.Time
+	fn   func()
+	c    chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, timer := range f.timers {
+		if timer == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.changed.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *fakeTimer) fire() {
+	if t.fn != nil {
+		t.fn()
+		return
+	}
+
+	t.c <- t.at
+}
@@ -0,0 +1,97 @@
+package clock_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
+)
+
+var start = time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+
+func TestFake(t *testing.T) {
+	t.Run("fires timers in order as it advances", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		var fired []string
+
+		fake.AfterFunc(2*time.Minute, func() { fired = append(fired, "second") })
+		fake.AfterFunc(time.Minute, func() { fired = append(fired, "first") })
+		fake.AfterFunc(3*time.Minute, func() { fired = append(fired, "third") })
+
+		fake.Advance(2 * time.Minute)
+
+		if want := []string{"first", "second"}; !reflect.DeepEqual(fired, want) {
+			t.Errorf("got %v want %v", fired, want)
+		}
+
+		if got := fake.Now(); !got.Equal(start.Add(2 * time.Minute)) {
+			t.Errorf("got now %v", got)
+		}
+
+		if fake.Pending() != 1 {
+			t.Errorf("got %d pending timers want 1", fake.Pending())
+		}
+	})
+
+	t.Run("fires timers scheduled while advancing", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		var firedAt []time.Time
+
+		var tick func()
+		tick = func() {
+			firedAt = append(firedAt, fake.Now())
+			fake.AfterFunc(time.Minute, tick)
+		}
+		fake.AfterFunc(time.Minute, tick)
+
+		fake.Advance(3 * time.Minute)
+
+		want := []time.Time{start.Add(time.Minute), start.Add(2 * time.Minute), start.Add(3 * time.Minute)}
+		if !reflect.DeepEqual(firedAt, want) {
+			t.Errorf("got %v want %v", firedAt, want)
+		}
+	})
+
+	t.Run("stopped timers don't fire", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		fired := false
+
+		timer := fake.AfterFunc(time.Minute, func() { fired = true })
+
+		if !timer.Stop() {
+			t.Error("expected stopping a pending timer to report true")
+		}
+		if timer.Stop() {
+			t.Error("expected stopping twice to report false")
+		}
+
+		fake.Advance(time.Hour)
+
+		if fired {
+			t.Error("stopped timer fired")
+		}
+	})
+
+	t.Run("sleeps until advanced", func(t *testing.T) {
+		fake := clock.NewFake(start)
+		woke := make(chan time.Time)
+
+		go func() {
+			clock.Sleep(fake, time.Second)
+			woke <- fake.Now()
+		}()
+
+		fake.BlockUntil(1)
+		fake.Advance(time.Second)
+
+		select {
+		case got := <-woke:
+			if !got.Equal(start.Add(time.Second)) {
+				t.Errorf("woke at %v", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("sleeper didn't wake")
+		}
+	})
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"bytes"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
 const write = "write"
@@ -69,6 +72,47 @@ func (s *SpyTime) Sleep(duration time.Duration) {
 	s.durationSlept = duration
 }
 
+func TestCountdownOnAClock(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	out := &syncBuffer{}
+	done := make(chan struct{})
+
+	go func() {
+		Countdown(out, NewClockSleeper(time.Second, fake))
+		close(done)
+	}()
+
+	for _, want := range []string{"3\n", "3\n2\n", "3\n2\n1\n"} {
+		fake.BlockUntil(1)
+		if got := out.String(); got != want {
+			t.Fatalf("got %q want %q", got, want)
+		}
+		fake.Advance(time.Second)
+	}
+
+	<-done
+	if got := out.String(); got != "3\n2\n1\nGo!" {
+		t.Errorf("got %q want %q", got, "3\n2\n1\nGo!")
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestConfigurableSleeper(t *testing.T) {
 	sleepTime := 5 * time.Second
 
@@ -5,6 +5,8 @@ import (
 	"io"
 	"os"
 	"time"
+
+	"github.com/zmwilliam/learn-go-with-tests/clock"
 )
 
 const countdownStart = 3
@@ -32,7 +34,12 @@ func (c *ConfigurableSleeper) Sleep() {
 	c.sleep(c.duration)
 }
 
+// NewClockSleeper sleeps for duration on c.
+func NewClockSleeper(duration time.Duration, c clock.Clock) *ConfigurableSleeper {
+	return &ConfigurableSleeper{duration, func(d time.Duration) { clock.Sleep(c, d) }}
+}
+
 func main() {
-	sleeper := &ConfigurableSleeper{1 * time.Second, time.Sleep}
+	sleeper := NewClockSleeper(1*time.Second, clock.Real{})
 	Countdown(os.Stdout, sleeper)
 }